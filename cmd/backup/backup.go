@@ -1,10 +1,15 @@
 package backup
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/fr12k/cloudsql-exporter/cmd"
 	"github.com/fr12k/cloudsql-exporter/pkg/backup"
+	"github.com/fr12k/cloudsql-exporter/pkg/cloudsql"
+	bakstorage "github.com/fr12k/cloudsql-exporter/pkg/storage"
+	"github.com/fr12k/cloudsql-exporter/pkg/version"
 
 	"github.com/spf13/cobra"
 )
@@ -16,6 +21,24 @@ type BackupOptions struct {
 	Compression           bool
 	EnsureIamBindings     bool
 	EnsureIamBindingsTemp bool
+
+	LastBackupTime string
+	Destination    string
+
+	Concurrency   int
+	RateLimit     uint64
+	RateLimitUnit string
+	FailFast      bool
+
+	Checksum bool
+
+	KMSKey string
+
+	DeepVerify       bool
+	StatsParallelism int
+
+	CredentialsFile string
+	Endpoint        string
 }
 
 var backupOpts = &BackupOptions{}
@@ -38,6 +61,25 @@ func init() {
 	backupCmd.Flags().BoolVar(&backupOpts.Compression, "compression", false, "Enable gz compression for the exported backup data file. (default: false)")
 	backupCmd.Flags().BoolVar(&backupOpts.EnsureIamBindings, "ensure-iam-bindings", false, "Ensure needed IAM permission on the target bucket are set for the Cloud SQL instance service account. (default: false)")
 	backupCmd.Flags().BoolVar(&backupOpts.EnsureIamBindingsTemp, "ensure-iam-bindings-temp", false, "Ensure needed IAM permission on the target bucket are set and removed afterwards. (default: false)")
+
+	backupCmd.Flags().StringVar(&backupOpts.LastBackupTime, "last-backup-ts", "", "RFC3339 timestamp of the previous backup. When set, only rows changed since this time are exported as an incremental backup. (default: full backup)")
+
+	backupCmd.Flags().StringVar(&backupOpts.Destination, "destination", "", "Archive destination URL: gs://..., s3://..., az://... or file://... (default: gs://<bucket>)")
+
+	backupCmd.Flags().IntVar(&backupOpts.Concurrency, "concurrency", 1, "Number of instances to back up in parallel. (default: 1)")
+	backupCmd.Flags().Uint64Var(&backupOpts.RateLimit, "ratelimit", 0, "Maximum aggregate upload throughput to the destination backend across all workers. 0 means unlimited. (default: 0)")
+	backupCmd.Flags().StringVar(&backupOpts.RateLimitUnit, "ratelimit-unit", "MB", "Unit for --ratelimit: MB or MiB. (default: MB)")
+	backupCmd.Flags().BoolVar(&backupOpts.FailFast, "fail-fast", false, "Abort the whole run as soon as a single instance/database export fails, instead of collecting errors. (default: false)")
+
+	backupCmd.Flags().BoolVar(&backupOpts.Checksum, "checksum", false, "Re-hash every exported backup object and record its checksum in the manifest, so restore can verify integrity before importing. (default: false)")
+
+	backupCmd.Flags().StringVar(&backupOpts.KMSKey, "kms-key", "", "Cloud KMS key (full resource name, e.g. projects/p/locations/l/keyRings/r/cryptoKeys/k) to encrypt every exported backup object at rest with. Restore needs the same key to decrypt. (default: leave objects as Cloud SQL exported them)")
+
+	backupCmd.Flags().BoolVar(&backupOpts.DeepVerify, "deep-verify", false, "Record a per-table content checksum alongside row counts (requires --stats), so restore can catch data that changed without the row count moving. (default: false)")
+	backupCmd.Flags().IntVar(&backupOpts.StatsParallelism, "stats-parallelism", 1, "Number of per-table content checksum queries to run concurrently when --deep-verify is set. (default: 1)")
+
+	backupCmd.Flags().StringVar(&backupOpts.CredentialsFile, "credentials-file", "", "Service account JSON key file to use instead of Application Default Credentials. (default: use ADC)")
+	backupCmd.Flags().StringVar(&backupOpts.Endpoint, "endpoint", "", "Override the GCP API base URL for the sqladmin/storage/secretmanager clients, e.g. to target an emulator or the Cloud SQL Auth Proxy. (default: the production API)")
 }
 
 func execute(ccmd *cobra.Command, args []string) error {
@@ -45,12 +87,14 @@ func execute(ccmd *cobra.Command, args []string) error {
 	project := GetString(ccmd, "project")
 	instance := GetString(ccmd, "instance")
 	user := GetString(ccmd, "user")
+	region := GetString(ccmd, "region")
 
 	opts := backup.BackupOptions{
 		Bucket:   bucket,
 		Project:  project,
 		Instance: instance,
 		User:     user,
+		Region:   region,
 
 		ExportStats: true,
 		Password:    backupOpts.Password,
@@ -58,6 +102,53 @@ func execute(ccmd *cobra.Command, args []string) error {
 		Compression:           backupOpts.Compression,
 		EnsureIamBindings:     backupOpts.EnsureIamBindings,
 		EnsureIamBindingsTemp: backupOpts.EnsureIamBindingsTemp,
+
+		Concurrency: backupOpts.Concurrency,
+		FailFast:    backupOpts.FailFast,
+
+		Checksum: backupOpts.Checksum,
+
+		KMSKey: backupOpts.KMSKey,
+
+		DeepVerify:       backupOpts.DeepVerify,
+		StatsParallelism: backupOpts.StatsParallelism,
+
+		Version: version.BuildVersion,
+	}
+
+	switch backupOpts.RateLimitUnit {
+	case "MiB":
+		opts.RateLimitMBps = uint64(float64(backupOpts.RateLimit) * 1.048576)
+	default:
+		opts.RateLimitMBps = backupOpts.RateLimit
+	}
+
+	if backupOpts.LastBackupTime != "" {
+		lastBackupTime, err := time.Parse(time.RFC3339, backupOpts.LastBackupTime)
+		if err != nil {
+			return fmt.Errorf("invalid --last-backup-ts: %w", err)
+		}
+		opts.LastBackupTime = lastBackupTime
+	}
+
+	if backupOpts.Destination != "" {
+		backend, err := bakstorage.NewBackend(context.Background(), backupOpts.Destination)
+		if err != nil {
+			return fmt.Errorf("invalid --destination: %w", err)
+		}
+		opts.Backend = backend
+	}
+
+	if backupOpts.CredentialsFile != "" {
+		ts, err := cloudsql.LoadJWTKeyFile(context.Background(), backupOpts.CredentialsFile)
+		if err != nil {
+			return fmt.Errorf("invalid --credentials-file: %w", err)
+		}
+		opts.ClientOptions = append(opts.ClientOptions, cloudsql.WithTokenSource(ts))
+	}
+
+	if backupOpts.Endpoint != "" {
+		opts.ClientOptions = append(opts.ClientOptions, cloudsql.WithEndpoint(backupOpts.Endpoint))
 	}
 
 	locations, err := backup.Backup(&opts)
@@ -0,0 +1,75 @@
+package prune
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fr12k/cloudsql-exporter/cmd"
+	"github.com/fr12k/cloudsql-exporter/pkg/backup"
+
+	"github.com/spf13/cobra"
+)
+
+type PruneOptions struct {
+	KeepLast    int
+	KeepDaily   int
+	KeepWeekly  int
+	KeepMonthly int
+	MinAge      time.Duration
+	DryRun      bool
+}
+
+var pruneOpts = &PruneOptions{}
+
+var pruneCmd = &cobra.Command{
+	Use:     "prune",
+	Example: "cloudsql-exporter prune --bucket=database-backup-bucket --project=f**********g --instance=db-instance-to-backup --keep-daily=7 --keep-weekly=4 --keep-monthly=12",
+	Short:   "Delete backups that violate a GFS-style retention policy.",
+	Long:    `This scans a bucket's backups and deletes objects that violate a configurable GFS-style (grandfather-father-son) retention policy, skipping any backup marked retain in its manifest or still referenced by a dependent incremental backup.`,
+	RunE:    execute,
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(pruneCmd)
+
+	pruneCmd.Flags().IntVar(&pruneOpts.KeepLast, "keep-last", 0, "Always keep the N most recent backups per instance/database. (default: 0, disabled)")
+	pruneCmd.Flags().IntVar(&pruneOpts.KeepDaily, "keep-daily", 0, "Keep the newest backup for each of the last N days. (default: 0, disabled)")
+	pruneCmd.Flags().IntVar(&pruneOpts.KeepWeekly, "keep-weekly", 0, "Keep the newest backup for each of the last N ISO weeks. (default: 0, disabled)")
+	pruneCmd.Flags().IntVar(&pruneOpts.KeepMonthly, "keep-monthly", 0, "Keep the newest backup for each of the last N calendar months. (default: 0, disabled)")
+	pruneCmd.Flags().DurationVar(&pruneOpts.MinAge, "min-age", 0, "Never delete a backup younger than this duration, regardless of the rest of the policy. (default: 0, disabled)")
+	pruneCmd.Flags().BoolVar(&pruneOpts.DryRun, "dry-run", false, "Log what would be deleted without deleting anything. (default: false)")
+}
+
+func execute(ccmd *cobra.Command, args []string) error {
+	bucket := GetString(ccmd, "bucket")
+	instance := GetString(ccmd, "instance")
+
+	opts := &backup.PruneOptions{
+		Bucket:   bucket,
+		Instance: instance,
+		Policy: backup.RetentionPolicy{
+			KeepLast:    pruneOpts.KeepLast,
+			KeepDaily:   pruneOpts.KeepDaily,
+			KeepWeekly:  pruneOpts.KeepWeekly,
+			KeepMonthly: pruneOpts.KeepMonthly,
+			MinAge:      pruneOpts.MinAge,
+		},
+		DryRun: pruneOpts.DryRun,
+	}
+
+	deleted, err := backup.Prune(opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Pruned backups %v", deleted)
+	return nil
+}
+
+func GetString(ccmd *cobra.Command, name string) string {
+	bucket, err := ccmd.Flags().GetString(name)
+	if err != nil {
+		panic(err)
+	}
+	return bucket
+}
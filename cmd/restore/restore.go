@@ -1,8 +1,10 @@
 package restore
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/fr12k/cloudsql-exporter/cmd"
-	"github.com/fr12k/cloudsql-exporter/pkg/cloudsql"
 	"github.com/fr12k/cloudsql-exporter/pkg/restore"
 
 	"github.com/spf13/cobra"
@@ -12,6 +14,27 @@ type RestoreOptions struct {
 	File        string
 	Cleanup     bool
 	StoreSecret bool
+	Timestamp   string
+	PointInTime string
+
+	// Password connects to the source instance (--instance) so Restore can
+	// verify the restored data against it and, combined with --cleanup,
+	// delete the ephemeral restore instance once that verification passes.
+	// Empty skips verification entirely.
+	Password string
+
+	DeepVerify       bool
+	StatsParallelism int
+
+	// KMSKey, when set, tells Restore that --file was encrypted with this
+	// Cloud KMS key by backup's --kms-key and must be decrypted before
+	// import.
+	KMSKey string
+
+	// StorageBackend picks which BlobStore --bucket's manifest, user and
+	// stats files are read from when --bucket is a bare name (gs:// or
+	// s3:// bucket URIs are honored regardless). One of "gcs" or "s3".
+	StorageBackend string
 }
 
 var restoreOpts = &RestoreOptions{}
@@ -28,8 +51,18 @@ func init() {
 	cmd.RootCmd.AddCommand(restoreCmd)
 	cmd.AddRequiredFlag(restoreCmd, &restoreOpts.File, "file", "The full location of the file to restore cloudsql instance from. (required)")
 
-	restoreCmd.Flags().BoolVar(&restoreOpts.Cleanup, "cleanup", false, "Remove the CloudSQL restore instance after the restore integrity check passes. (default false)")
+	restoreCmd.Flags().BoolVar(&restoreOpts.Cleanup, "cleanup", false, "Remove the CloudSQL restore instance after the restore integrity check passes. Requires --password, since cleanup is gated on Verify passing. (default false)")
+	restoreCmd.Flags().StringVar(&restoreOpts.Password, "password", "", "Cloud SQL password for the source instance's default user, used to verify the restored data against it. (default: skip verification)")
 	restoreCmd.Flags().BoolVar(&restoreOpts.StoreSecret, "store-password", true, "Store the password for the restore CloudSQL instance root user in the GCP Secret Manager (RESTORE-{INSTANCE_NAME}). (default true)")
+	restoreCmd.Flags().StringVar(&restoreOpts.Timestamp, "timestamp", "", "RFC3339 timestamp to restore to. When set, the closest preceding full backup is imported, then --instance is cloned to this point in time with Cloud SQL's native PITR and the clone replaces the import-based restore instance. (default: replay --file as-is)")
+	restoreCmd.Flags().StringVar(&restoreOpts.PointInTime, "point-in-time", "", "RFC3339 timestamp to restore to using GCS object generations instead of Cloud SQL's native PITR: the newest full backup generation before this time is imported, then every binlogs/ incremental generation up to it is replayed. Takes precedence over --timestamp. (default: replay --file as-is)")
+
+	restoreCmd.Flags().BoolVar(&restoreOpts.DeepVerify, "deep-verify", false, "Recompute a per-table content checksum on the restore instance and compare it against the backup, in addition to the row-count check. (default: false)")
+	restoreCmd.Flags().IntVar(&restoreOpts.StatsParallelism, "stats-parallelism", 1, "Number of per-table content checksum queries to run concurrently when --deep-verify is set. (default: 1)")
+
+	restoreCmd.Flags().StringVar(&restoreOpts.KMSKey, "kms-key", "", "Cloud KMS key (full resource name) --file was encrypted with by backup's --kms-key. Required to decrypt it before import. (default: treat --file as plaintext)")
+
+	restoreCmd.Flags().StringVar(&restoreOpts.StorageBackend, "storage-backend", "gcs", "Object storage backend --bucket's manifest, user and stats files are read from when --bucket has no gs:// or s3:// scheme: gcs or s3. Does not apply to --point-in-time or the Cloud SQL service account bucket IAM bindings, which are GCS-only regardless. (default: gcs)")
 }
 
 func execute(ccmd *cobra.Command, args []string) error {
@@ -37,15 +70,36 @@ func execute(ccmd *cobra.Command, args []string) error {
 	project := GetString(ccmd, "project")
 	instance := GetString(ccmd, "instance")
 	user := GetString(ccmd, "user")
+	region := GetString(ccmd, "region")
+
+	switch restoreOpts.StorageBackend {
+	case "gcs":
+		// bucket is already a bare GCS bucket name by default; nothing to do.
+	case "s3":
+		if !strings.Contains(bucket, "://") {
+			bucket = "s3://" + bucket
+		}
+	default:
+		return fmt.Errorf("invalid --storage-backend %q: must be gcs or s3", restoreOpts.StorageBackend)
+	}
 
-	opts := &cloudsql.RestoreOptions{
+	opts := &restore.RestoreOptions{
 		Bucket:      bucket,
 		Project:     project,
 		Instance:    instance,
 		User:        user,
+		Region:      region,
 		File:        restoreOpts.File,
 		Cleanup:     restoreOpts.Cleanup,
+		Password:    restoreOpts.Password,
 		StoreSecret: restoreOpts.StoreSecret,
+		Timestamp:   restoreOpts.Timestamp,
+		PointInTime: restoreOpts.PointInTime,
+
+		DeepVerify:       restoreOpts.DeepVerify,
+		StatsParallelism: restoreOpts.StatsParallelism,
+
+		KMSKey: restoreOpts.KMSKey,
 	}
 
 	_, err := restore.Restore(opts)
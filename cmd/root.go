@@ -1,10 +1,22 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/fr12k/cloudsql-exporter/pkg/logging"
+	"github.com/fr12k/cloudsql-exporter/pkg/tracing"
 	"github.com/fr12k/cloudsql-exporter/pkg/version"
 	"github.com/spf13/cobra"
 )
 
+// otelShutdown flushes and closes the OpenTelemetry tracer provider
+// RootCmd's PersistentPreRunE configures; PersistentPostRunE calls it once
+// the command has run.
+var otelShutdown = func(context.Context) error { return nil }
+
 // rootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
 	Use:   "cloudsql-exporter",
@@ -13,6 +25,32 @@ var RootCmd = &cobra.Command{
 
 	SilenceUsage:  true,
 	SilenceErrors: true,
+
+	PersistentPreRunE: func(ccmd *cobra.Command, args []string) error {
+		logFormat, err := ccmd.Flags().GetString("log-format")
+		if err != nil {
+			return err
+		}
+		handler, err := logging.NewHandler(os.Stdout, logFormat, slog.LevelInfo)
+		if err != nil {
+			return fmt.Errorf("invalid --log-format: %w", err)
+		}
+		slog.SetDefault(slog.New(handler))
+
+		otelEndpoint, err := ccmd.Flags().GetString("otel-endpoint")
+		if err != nil {
+			return err
+		}
+		shutdown, err := tracing.Init(ccmd.Context(), otelEndpoint, "cloudsql-exporter")
+		if err != nil {
+			return fmt.Errorf("invalid --otel-endpoint: %w", err)
+		}
+		otelShutdown = shutdown
+		return nil
+	},
+	PersistentPostRunE: func(ccmd *cobra.Command, args []string) error {
+		return otelShutdown(ccmd.Context())
+	},
 }
 
 func init() {
@@ -20,6 +58,10 @@ func init() {
 	AddRequiredPersistentFlagShort(RootCmd, "project", "p", "The GCP project name that contains the Cloud SQL instance.")
 	AddRequiredPersistentFlagShort(RootCmd, "instance", "i", "The GCP Cloud SQL instance name to export/import data from.")
 	RootCmd.PersistentFlags().String("user", "", "The Cloud SQL user to connect to the database.")
+	RootCmd.PersistentFlags().String("region", "europe-west3", "The GCP region to create restore instances and password secrets in. (default: europe-west3)")
+
+	RootCmd.PersistentFlags().String("log-format", "pretty", "Log output format: pretty, json or logfmt. (default: pretty)")
+	RootCmd.PersistentFlags().String("otel-endpoint", "", "OTLP/gRPC endpoint to export OpenTelemetry spans for the restore pipeline to, e.g. localhost:4317. (default: tracing disabled)")
 
 	RootCmd.Version = version.BuildVersion
 }
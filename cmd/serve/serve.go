@@ -0,0 +1,97 @@
+package serve
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/fr12k/cloudsql-exporter/cmd"
+	"github.com/fr12k/cloudsql-exporter/pkg/cloudsql"
+	"github.com/fr12k/cloudsql-exporter/pkg/server"
+
+	"github.com/spf13/cobra"
+)
+
+type ServeOptions struct {
+	Addr    string
+	Workers int
+
+	// ScheduleConfig, when set, points at a YAML file listing backups to run
+	// on a cron schedule (see server.ScheduleConfig), turning this process
+	// into a self-contained daemon instead of a pure on-demand control-plane.
+	ScheduleConfig string
+
+	CredentialsFile string
+	Endpoint        string
+}
+
+var serveOpts = &ServeOptions{}
+
+var serveCmd = &cobra.Command{
+	Use:     "serve",
+	Example: "cloudsql-exporter serve --project=f**********g --addr :8080",
+	Short:   "Run an HTTP control-plane for triggering backups and restores.",
+	Long:    `This runs an HTTP API that lets webhook/cron/CI callers trigger backups and restores and poll their status, instead of only invoking the CLI directly. With --schedule-config it also runs its own cron schedules, so it can be deployed as a long-running Kubernetes Deployment or Cloud Run job with no external scheduler. GET /metrics exposes Prometheus counters for backup duration/success and restore verification failures, and GET /healthz is a liveness probe.`,
+	RunE:    execute,
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveOpts.Addr, "addr", ":8080", "Address to listen on. (default: :8080)")
+	serveCmd.Flags().IntVar(&serveOpts.Workers, "workers", 2, "Number of concurrent backup jobs the worker pool runs at once. (default: 2)")
+
+	serveCmd.Flags().StringVar(&serveOpts.ScheduleConfig, "schedule-config", "", "YAML file listing {project, instance, bucket, schedule} backups to run on a cron schedule, so this process can replace an external cron daemon. (default: don't schedule anything)")
+
+	serveCmd.Flags().StringVar(&serveOpts.CredentialsFile, "credentials-file", "", "Service account JSON key file to use instead of Application Default Credentials for the control-plane's own clients (restores, instance lookups, operation status). Backups it runs, on request or on schedule, still use ADC. (default: use ADC)")
+	serveCmd.Flags().StringVar(&serveOpts.Endpoint, "endpoint", "", "Override the GCP API base URL for the control-plane's own sqladmin/storage/secretmanager clients, e.g. to target an emulator or the Cloud SQL Auth Proxy. Backups it runs, on request or on schedule, still use the production API. (default: the production API)")
+}
+
+func execute(ccmd *cobra.Command, args []string) error {
+	project := GetString(ccmd, "project")
+	region := GetString(ccmd, "region")
+	ctx := context.Background()
+
+	var clientOpts []cloudsql.Option
+	if serveOpts.CredentialsFile != "" {
+		ts, err := cloudsql.LoadJWTKeyFile(ctx, serveOpts.CredentialsFile)
+		if err != nil {
+			return fmt.Errorf("invalid --credentials-file: %w", err)
+		}
+		clientOpts = append(clientOpts, cloudsql.WithTokenSource(ts))
+	}
+	if serveOpts.Endpoint != "" {
+		clientOpts = append(clientOpts, cloudsql.WithEndpoint(serveOpts.Endpoint))
+	}
+
+	srv, err := server.New(ctx, project, region, serveOpts.Workers, clientOpts...)
+	if err != nil {
+		return err
+	}
+
+	if serveOpts.ScheduleConfig != "" {
+		cfg, err := server.LoadScheduleConfig(serveOpts.ScheduleConfig)
+		if err != nil {
+			return fmt.Errorf("invalid --schedule-config: %w", err)
+		}
+		scheduler, err := server.NewScheduler(cfg)
+		if err != nil {
+			return fmt.Errorf("invalid --schedule-config: %w", err)
+		}
+		slog.Info("Starting scheduled backups", "config", serveOpts.ScheduleConfig, "backups", len(cfg.Backups))
+		scheduler.Start()
+		defer scheduler.Stop()
+	}
+
+	slog.Info("Starting control-plane HTTP server", "addr", serveOpts.Addr)
+	return http.ListenAndServe(serveOpts.Addr, srv.Routes())
+}
+
+func GetString(ccmd *cobra.Command, name string) string {
+	bucket, err := ccmd.Flags().GetString(name)
+	if err != nil {
+		panic(err)
+	}
+	return bucket
+}
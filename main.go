@@ -6,21 +6,14 @@ import (
 
 	"github.com/fr12k/cloudsql-exporter/cmd"
 	_ "github.com/fr12k/cloudsql-exporter/cmd/backup"
+	_ "github.com/fr12k/cloudsql-exporter/cmd/prune"
 	_ "github.com/fr12k/cloudsql-exporter/cmd/restore"
-
-	"github.com/dusted-go/logging/prettylog"
+	_ "github.com/fr12k/cloudsql-exporter/cmd/serve"
 )
 
 func main() {
-
-	prettyHandler := prettylog.NewHandler(&slog.HandlerOptions{
-		Level:       slog.LevelInfo,
-		AddSource:   false,
-		ReplaceAttr: nil,
-	})
-	logger := slog.New(prettyHandler)
-	slog.SetDefault(logger)
-
+	// cmd.RootCmd's PersistentPreRunE installs the real default logger once
+	// --log-format is parsed; this covers errors raised before that runs.
 	err := cmd.Execute()
 	if err != nil {
 		slog.Error("error executing command", "error", err)
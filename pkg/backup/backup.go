@@ -2,13 +2,17 @@ package backup
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"strings"
+	"sync"
 	"time"
 
-	secretmanager "cloud.google.com/go/secretmanager/apiv1beta2"
 	"cloud.google.com/go/storage"
-	"google.golang.org/api/sqladmin/v1"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
 
 	"github.com/fr12k/cloudsql-exporter/pkg/cloudsql"
 	bakstorage "github.com/fr12k/cloudsql-exporter/pkg/storage"
@@ -20,116 +24,418 @@ type BackupOptions struct {
 	Instance string
 	User     string
 
+	// Region is the GCP region CloudSQL creates resources in on the
+	// caller's behalf (restore instances, password secrets). Defaults to
+	// "europe-west3" when empty.
+	Region string
+
 	ExportStats bool   // Export tables statistics to be able to validate restored data integrity after restore
 	Password    string // Cloud SQL password for the user to connect to the database to export tables statistics to be able to validate restored data integrity
 
+	// DeepVerify, when set, additionally records a per-table content
+	// checksum alongside each table's row count, so Restore can catch data
+	// that silently changed without the row count moving. Adds one
+	// extra query per table, run with up to StatsParallelism at once.
+	DeepVerify bool
+	// StatsParallelism bounds how many tables' checksums DeepVerify
+	// computes concurrently. <= 0 behaves as 1.
+	StatsParallelism int
+
 	Compression           bool
 	EnsureIamBindings     bool
 	EnsureIamBindingsTemp bool
 
+	// Backend is the archive destination for this backup's artifacts. Cloud
+	// SQL's native export API only ever writes to GCS, so when Backend is not
+	// a *bakstorage.GCSBackend, Backup additionally copies each exported
+	// object from the GCS staging bucket to Backend once the export
+	// completes. Defaults to a GCSBackend over Bucket when nil.
+	Backend bakstorage.Backend
+
+	// LastBackupTime, when set, switches the backup into incremental mode:
+	// only rows that changed since this timestamp are exported, and the
+	// written manifest records this backup as a child of the backup taken at
+	// that time.
+	LastBackupTime time.Time
+
+	// Concurrency bounds how many instances are backed up at once. Defaults
+	// to 1 (today's strictly serial behavior) when zero or negative.
+	Concurrency int
+	// RateLimitMBps caps the aggregate upload throughput across all workers
+	// when syncing exports to a non-GCS Backend. Zero means unlimited.
+	RateLimitMBps uint64
+	// FailFast preserves today's behavior of aborting the whole run as soon
+	// as a single instance/database export fails. When false, errors are
+	// collected and returned together once every instance has been attempted.
+	FailFast bool
+
+	// Checksum, when set, re-reads every exported database object after
+	// ExportCloudSQLDatabase returns, computes its SHA-256 and CRC32C, and
+	// records them in the manifest alongside the row-count snapshot from
+	// ExportCloudSQLStatistics and Version, so Restore can verify backup
+	// integrity before importing. Adds one extra read of every exported
+	// object, so it's opt-in rather than always-on.
+	Checksum bool
+
+	// KMSKey, when set, encrypts every exported database object in place
+	// with a fresh per-object key wrapped by this Cloud KMS key (a full
+	// resource name), after the export completes and before Checksum runs,
+	// so Restore's recorded checksum matches what's actually stored.
+	// Requires the caller to pass the same KMSKey to restore.RestoreOptions
+	// to decrypt again.
+	KMSKey string
+
 	Version string
+
+	// ClientOptions configures the sqladmin, storage and secretmanager
+	// clients Backup builds, e.g. cloudsql.WithTokenSource to run against a
+	// non-GCP environment instead of only Application Default Credentials.
+	ClientOptions []cloudsql.Option
 }
 
-func Backup(opts *BackupOptions) (backupPaths []string, rerr error) {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+// instanceResult carries the per-instance outcome so it can be merged back
+// into Backup's aggregate result from concurrent workers.
+type instanceResult struct {
+	instance string
+	backups  []string
+	duration time.Duration
+	err      error
+}
 
-	sqlAdminSvc, err := sqladmin.NewService(ctx)
+// writeManifest uploads the backup's manifest JSON next to the data it describes.
+func writeManifest(ctx context.Context, storageSvc *storage.Client, loc bakstorage.Location, manifest bakstorage.Manifest) error {
+	data, err := manifest.Marshal()
 	if err != nil {
-		slog.Error("error init sqladmin.Service client", "error", err)
-		return nil, err
+		return err
+	}
+
+	writer := storageSvc.Bucket(loc.Bucket).Object(loc.ManifestLocation()).NewWriter(ctx)
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return err
 	}
+	return writer.Close()
+}
+
+// syncToBackendRateLimited copies every GCS object written by the native
+// Cloud SQL export (gcsURIs, of the form gs://<bucket>/<key>) to the
+// configured destination backend, preserving the bucket-relative key. Upload
+// throughput is throttled by limiter, which is shared across every
+// concurrent worker so the aggregate egress stays under
+// BackupOptions.RateLimitMBps.
+func syncToBackendRateLimited(ctx context.Context, storageSvc *storage.Client, backend bakstorage.Backend, limiter *rate.Limiter, gcsURIs []string) error {
+	for _, uri := range gcsURIs {
+		loc := bakstorage.NewLocation(uri)
+		key := strings.TrimPrefix(uri, fmt.Sprintf("gs://%s/", loc.Bucket))
+
+		reader, err := storageSvc.Bucket(loc.Bucket).Object(key).NewReader(ctx)
+		if err != nil {
+			return fmt.Errorf("open %s: %w", uri, err)
+		}
+
+		err = backend.Upload(ctx, key, &rateLimitedReader{ctx: ctx, r: reader, lim: limiter})
+		reader.Close()
+		if err != nil {
+			return fmt.Errorf("upload %s to destination backend: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// encryptObjects encrypts every object named by gcsURIs (of the form
+// gs://<bucket>/<key>) in place via cls.EncryptExportedObject, using a
+// fresh per-object key wrapped by the Cloud KMS key kmsKeyName. Cloud SQL's
+// native export API can only write plaintext straight to GCS, so this
+// re-reads and re-uploads each object after the export completes, the same
+// way checksumObjects does for BackupOptions.Checksum.
+func encryptObjects(cls *cloudsql.CloudSQL, kmsKeyName string, gcsURIs []string) error {
+	for _, uri := range gcsURIs {
+		loc := bakstorage.NewLocation(uri)
+		key := strings.TrimPrefix(uri, fmt.Sprintf("gs://%s/", loc.Bucket))
+		if err := cls.EncryptExportedObject(kmsKeyName, loc.Bucket, key); err != nil {
+			return fmt.Errorf("encrypt %s: %w", uri, err)
+		}
+	}
+	return nil
+}
+
+// checksumObjects re-reads every object named by gcsURIs (of the form
+// gs://<bucket>/<key>) and returns its recorded digest, so Backup can attach
+// them to the manifest for BackupOptions.Checksum.
+func checksumObjects(ctx context.Context, storageSvc *storage.Client, gcsURIs []string) ([]bakstorage.ObjectChecksum, error) {
+	objects := make([]bakstorage.ObjectChecksum, 0, len(gcsURIs))
+	for _, uri := range gcsURIs {
+		loc := bakstorage.NewLocation(uri)
+		key := strings.TrimPrefix(uri, fmt.Sprintf("gs://%s/", loc.Bucket))
 
-	storageSvc, err := storage.NewClient(ctx)
+		reader, err := storageSvc.Bucket(loc.Bucket).Object(key).NewReader(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("open %s: %w", uri, err)
+		}
+		digest, err := bakstorage.DigestReader(reader)
+		reader.Close()
+		if err != nil {
+			return nil, fmt.Errorf("checksum %s: %w", uri, err)
+		}
+
+		objects = append(objects, bakstorage.ObjectChecksum{
+			URI:      uri,
+			Database: loc.Database,
+			Digest:   digest,
+		})
+	}
+	return objects, nil
+}
+
+// rateLimitedReader throttles reads to stay under limiter's configured rate,
+// so the aggregate egress of every concurrent worker sharing it stays under
+// BackupOptions.RateLimitMBps.
+type rateLimitedReader struct {
+	ctx context.Context
+	r   io.Reader
+	lim *rate.Limiter
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if werr := r.lim.WaitN(r.ctx, n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+func backupInstance(ctx context.Context, cls *cloudsql.CloudSQL, storageSvc *storage.Client, backend bakstorage.Backend, limiter *rate.Limiter, opts *BackupOptions, instance string, databases []string) instanceResult {
+	start := time.Now()
+	result := instanceResult{instance: instance}
+
+	slog.Info("Exporting backup for instance", "instance", instance)
+
+	if opts.EnsureIamBindings || opts.EnsureIamBindingsTemp {
+		sqlAdminSvcAccount, err := cls.GetSvcAcctForCloudSQLInstance(instance, "")
+		if err != nil {
+			slog.Error("error get service account for instance", "instance", instance, "error", err)
+			result.err = err
+			return result
+		}
+		if opts.EnsureIamBindingsTemp {
+			defer func() {
+				remover, ok := backend.(interface{ RemoveWriter(string) error })
+				if !ok {
+					slog.Info("backend does not support temporary IAM bindings, skipping removal", "service_account", sqlAdminSvcAccount)
+					return
+				}
+				if err := remover.RemoveWriter(sqlAdminSvcAccount); err != nil {
+					slog.Error("error remove backend writer binding", "service_account", sqlAdminSvcAccount, "error", err)
+				}
+			}()
+		}
+		if err := backend.EnsureWriter(sqlAdminSvcAccount); err != nil {
+			slog.Error("error ensure backend writer binding", "service_account", sqlAdminSvcAccount, "error", err)
+			result.err = err
+			return result
+		}
+	}
+
+	backupLocation := bakstorage.Location{
+		Bucket:      opts.Bucket,
+		Instance:    instance,
+		Path:        fmt.Sprintf("%s/cloudsql/", instance),
+		Time:        time.Now().Format("20060102T150405"),
+		Compression: opts.Compression,
+	}
+	if !opts.LastBackupTime.IsZero() {
+		backupLocation.Kind = bakstorage.KindIncremental
+	}
+
+	users, err := cls.ExportCloudSQLUser(backupLocation)
 	if err != nil {
-		slog.Error("init storage.Service client", "error", err)
-		return nil, err
+		slog.Error("error export cloudsql user", "databases", databases, "instance", instance, "error", err)
+		result.err = err
+		return result
 	}
+	slog.Info("Exported cloudsql users", "users", users)
 
-	secretSvc, err := secretmanager.NewClient(ctx)
+	var stats map[string]*cloudsql.CloudSQLStatistic
+	if opts.ExportStats {
+		var err error
+		stats, err = cls.ExportCloudSQLStatistics(backupLocation, databases, opts.User, opts.Password, opts.DeepVerify, opts.StatsParallelism)
+		if err != nil {
+			slog.Error("error export cloudsql statistics", "databases", databases, "instance", instance, "error", err)
+			result.err = err
+			return result
+		}
+		slog.Info("Exported cloudsql statistics", "stats", stats)
+	}
+
+	locations, tableCursors, err := cls.ExportCloudSQLDatabase(backupLocation, databases, opts.User, opts.Password, opts.LastBackupTime)
 	if err != nil {
-		slog.Error("init secretmanager.Service client", "error", err)
-		return nil, err
+		slog.Error("error export cloudsql database", "databases", databases, "instance", instance, "error", err)
+		result.err = err
+		return result
 	}
+	result.backups = locations
 
-	cls := cloudsql.NewCloudSQL(ctx, sqlAdminSvc, storageSvc, secretSvc, opts.Project)
+	if opts.KMSKey != "" {
+		if err := encryptObjects(cls, opts.KMSKey, locations); err != nil {
+			slog.Error("error encrypt exported backup objects", "instance", instance, "error", err)
+			result.err = err
+			return result
+		}
+	}
 
-	instances, err := cls.EnumerateCloudSQLDatabaseInstances(opts.Instance)
+	engineVersion, err := cls.InstanceEngineVersion(instance)
 	if err != nil {
-		slog.Error("error reading cloudsql instances", "error", err)
-		return nil, err
+		slog.Error("error read instance engine version", "instance", instance, "error", err)
+		result.err = err
+		return result
 	}
 
-	for instance, databases := range instances {
-		slog.Info("Exporting backup for instance", "instance", string(instance))
+	manifest := bakstorage.Manifest{
+		Tables:        tableCursors,
+		EngineVersion: engineVersion,
+		CreatedAt:     time.Now(),
+	}
+	if opts.LastBackupTime.IsZero() {
+		manifest.BaseBackup = backupLocation.DatabaseLocation(databases[0])
+		for _, database := range databases[1:] {
+			manifest.Parents = append(manifest.Parents, backupLocation.DatabaseLocation(database))
+		}
+	} else {
+		parentLocation := backupLocation
+		parentLocation.Time = opts.LastBackupTime.Format("20060102T150405")
+		for _, database := range databases {
+			manifest.Parents = append(manifest.Parents, parentLocation.DatabaseLocation(database))
+		}
+	}
 
-		if opts.EnsureIamBindings || opts.EnsureIamBindingsTemp {
-			sqlAdminSvcAccount, err := cls.GetSvcAcctForCloudSQLInstance(string(instance), "")
-			if err != nil {
-				slog.Error("error get service account for instance", "instance", string(instance), "error", err)
-				return nil, err
-			}
-			if opts.EnsureIamBindingsTemp {
-				defer func() {
-					err = cls.RemoveRoleBindingToGCSBucket(opts.Bucket, "roles/storage.objectCreator", sqlAdminSvcAccount, string(instance))
-					if err != nil {
-						slog.Error("error remove role binding roles/storage.objectCreator", "service_account", sqlAdminSvcAccount, "error", err)
-						rerr = err
-					}
-					err = cls.RemoveRoleBindingToGCSBucket(opts.Bucket, "roles/storage.objectViewer", sqlAdminSvcAccount, string(instance))
-					if err != nil {
-						slog.Error("error remove role binding roles/storage.objectViewer", "service_account", sqlAdminSvcAccount, "error", err)
-						rerr = err
-					}
-				}()
-			}
-			err = cls.AddRoleBindingToGCSBucket(opts.Bucket, "roles/storage.objectCreator", sqlAdminSvcAccount, string(instance))
-			if err != nil {
-				slog.Error("error add role binding roles/storage.objectCreator", "service_account", sqlAdminSvcAccount, "error", err)
-				return nil, err
-			}
-			err = cls.AddRoleBindingToGCSBucket(opts.Bucket, "roles/storage.objectViewer", sqlAdminSvcAccount, string(instance))
-			if err != nil {
-				slog.Error("error add role binding roles/storage.objectViewer", "service_account", sqlAdminSvcAccount, "error", err)
-				return nil, err
+	if opts.Checksum {
+		objects, err := checksumObjects(ctx, storageSvc, locations)
+		if err != nil {
+			slog.Error("error checksum exported backup objects", "instance", instance, "error", err)
+			result.err = err
+			return result
+		}
+		manifest.Objects = objects
+		manifest.Version = opts.Version
+
+		if len(stats) > 0 {
+			rowCounts := make(map[string]int64, len(stats))
+			for table, stat := range stats {
+				rowCounts[table] = stat.RowCount
 			}
+			manifest.RowCounts = rowCounts
 		}
+	}
 
-		backupLocation := bakstorage.Location{
-			Bucket:      opts.Bucket,
-			Instance:    string(instance),
-			Path:        fmt.Sprintf("%s/cloudsql/", string(instance)),
-			Time:        time.Now().Format("20060102T150405"),
-			Compression: opts.Compression,
+	if err := writeManifest(ctx, storageSvc, backupLocation, manifest); err != nil {
+		slog.Error("error write backup manifest", "instance", instance, "error", err)
+		result.err = err
+		return result
+	}
+
+	if _, isGCS := backend.(*bakstorage.GCSBackend); !isGCS {
+		if err := syncToBackendRateLimited(ctx, storageSvc, backend, limiter, locations); err != nil {
+			slog.Error("error sync backup to destination backend", "instance", instance, "error", err)
+			result.err = err
+			return result
 		}
+	}
+
+	result.duration = time.Since(start)
+	return result
+}
+
+func Backup(opts *BackupOptions) (backupPaths []string, rerr error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sqlAdminSvc, storageSvc, secretSvc, kmsSvc, err := cloudsql.NewClients(ctx, opts.ClientOptions...)
+	if err != nil {
+		slog.Error("error init GCP clients", "error", err)
+		return nil, err
+	}
+
+	region := opts.Region
+	if region == "" {
+		region = "europe-west3"
+	}
+	cls := cloudsql.NewCloudSQL(ctx, sqlAdminSvc, storageSvc, secretSvc, kmsSvc, opts.Project, region)
 
-		users, err := cls.ExportCloudSQLUser(backupLocation)
+	backend := opts.Backend
+	if backend == nil {
+		backend, err = bakstorage.NewGCSBackend(ctx, opts.Bucket)
 		if err != nil {
-			slog.Error("error export cloudsql user", "databases", databases, "instance", string(instance), "error", err)
+			slog.Error("init default GCS backend", "error", err)
 			return nil, err
 		}
+	}
 
-		slog.Info("Exported cloudsql users", "users", users)
+	instances, err := cls.EnumerateCloudSQLDatabaseInstances(opts.Instance)
+	if err != nil {
+		slog.Error("error reading cloudsql instances", "error", err)
+		return nil, err
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var limiter *rate.Limiter
+	if opts.RateLimitMBps > 0 {
+		bytesPerSec := float64(opts.RateLimitMBps) * 1e6
+		limiter = rate.NewLimiter(rate.Limit(bytesPerSec), int(bytesPerSec))
+	} else {
+		limiter = rate.NewLimiter(rate.Inf, 0)
+	}
 
-		if opts.ExportStats {
-			stats, err := cls.ExportCloudSQLStatistics(backupLocation, databases, opts.User, opts.Password)
-			if err != nil {
-				slog.Error("error export cloudsql statistics", "databases", databases, "instance", string(instance), "error", err)
-				return nil, err
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+
+	var mu sync.Mutex
+	var results []instanceResult
+
+	for instance, databases := range instances {
+		instance, databases := string(instance), databases.Items()
+		g.Go(func() error {
+			result := backupInstance(gctx, cls, storageSvc, backend, limiter, opts, instance, databases)
+
+			mu.Lock()
+			results = append(results, result)
+			mu.Unlock()
+
+			if result.err != nil && opts.FailFast {
+				return result.err
 			}
+			return nil
+		})
+	}
 
-			slog.Info("Exported cloudsql statistics", "stats", stats)
-		}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
 
-		locations, err := cls.ExportCloudSQLDatabase(backupLocation, databases)
-		if err != nil {
-			slog.Error("error export cloudsql database", "databases", databases, "instance", string(instance), "error", err)
-			return nil, err
+	var errs []error
+	for _, result := range results {
+		if result.err != nil {
+			errs = append(errs, fmt.Errorf("instance %s: %w", result.instance, result.err))
+			continue
 		}
-		backupPaths = append(backupPaths, locations...)
+		backupPaths = append(backupPaths, result.backups...)
+		throughput := 0.0
+		if result.duration > 0 {
+			throughput = float64(len(result.backups)) / result.duration.Seconds()
+		}
+		slog.Info("Exported instance backup", "instance", result.instance, "duration", result.duration, "databases_per_second", throughput)
+	}
+
+	if len(errs) > 0 {
+		rerr = errors.Join(errs...)
 	}
 
-	slog.Info("Backup complete", "backups", backupPaths)
+	slog.Info("Backup complete", "backups", backupPaths, "errors", len(errs))
 
-	return backupPaths, nil
+	return backupPaths, rerr
 }
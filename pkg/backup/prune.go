@@ -0,0 +1,222 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	gcs "cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+
+	bakstorage "github.com/fr12k/cloudsql-exporter/pkg/storage"
+)
+
+// RetentionPolicy is a GFS-style (grandfather-father-son) backup retention
+// policy. A backup is kept if it is among the KeepLast most recent, or the
+// newest backup within its calendar day/ISO week/calendar month for each of
+// the last KeepDaily/KeepWeekly/KeepMonthly such periods. MinAge additionally
+// protects every backup younger than it from deletion, regardless of the
+// rest of the policy.
+type RetentionPolicy struct {
+	KeepLast    int
+	KeepDaily   int
+	KeepWeekly  int
+	KeepMonthly int
+	MinAge      time.Duration
+}
+
+// PruneOptions configures Prune.
+type PruneOptions struct {
+	Bucket string
+	// Instance, when set, restricts pruning to backups under this instance's
+	// prefix. Empty means every instance in Bucket.
+	Instance string
+	Policy   RetentionPolicy
+	// DryRun logs what would be deleted without deleting anything.
+	DryRun bool
+}
+
+// backupRun is a single exported database object together with the metadata
+// needed to decide whether RetentionPolicy keeps it.
+type backupRun struct {
+	object string // bucket-relative object name
+	uri    string
+	t      time.Time
+}
+
+// Prune scans opts.Bucket for exported database objects (<instance>/cloudsql/<db>-<time>[.incr].sql[.gz]),
+// groups them by instance+database using storage.NewLocation, and deletes
+// every object opts.Policy does not keep. A backup is never deleted if it is
+// the base_backup of a manifest with retain: true, or if it is listed among
+// any manifest's parents.
+func Prune(opts *PruneOptions) ([]string, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	storageSvc, err := gcs.NewClient(ctx)
+	if err != nil {
+		slog.Error("init storage.Service client", "error", err)
+		return nil, err
+	}
+
+	bucket := storageSvc.Bucket(opts.Bucket)
+
+	groups, protected, err := scanBucket(ctx, bucket, opts)
+	if err != nil {
+		slog.Error("error scan bucket for prunable backups", "bucket", opts.Bucket, "error", err)
+		return nil, err
+	}
+
+	now := time.Now()
+
+	var deleted []string
+	for key, runs := range groups {
+		keep := applyRetentionPolicy(runs, opts.Policy, now)
+		for _, r := range runs {
+			if keep[r.object] || protected[r.uri] {
+				continue
+			}
+
+			if opts.DryRun {
+				slog.Info("Prune would delete backup", "group", key, "object", r.object, "age", now.Sub(r.t))
+				deleted = append(deleted, r.uri)
+				continue
+			}
+
+			if err := bucket.Object(r.object).Delete(ctx); err != nil {
+				slog.Error("error delete pruned backup object", "object", r.object, "error", err)
+				return nil, err
+			}
+			slog.Info("Pruned backup", "group", key, "object", r.object, "age", now.Sub(r.t))
+			deleted = append(deleted, r.uri)
+		}
+	}
+
+	return deleted, nil
+}
+
+// scanBucket lists every object under opts.Bucket (restricted to
+// opts.Instance's prefix when set), grouping exported database objects by
+// instance+database and collecting the set of backup URIs protected from
+// deletion: a manifest's base_backup when its retain flag is set, and every
+// manifest's parents.
+func scanBucket(ctx context.Context, bucket *gcs.BucketHandle, opts *PruneOptions) (map[string][]backupRun, map[string]bool, error) {
+	prefix := ""
+	if opts.Instance != "" {
+		prefix = opts.Instance + "/"
+	}
+
+	groups := map[string][]backupRun{}
+	protected := map[string]bool{}
+
+	it := bucket.Objects(ctx, &gcs.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch {
+		case strings.HasSuffix(attrs.Name, ".sql") || strings.HasSuffix(attrs.Name, ".sql.gz"):
+			loc := bakstorage.NewLocation(fmt.Sprintf("gs://%s/%s", opts.Bucket, attrs.Name))
+			t, err := time.Parse("20060102T150405", loc.Time)
+			if err != nil {
+				slog.Error("skip backup object with unparsable timestamp", "object", attrs.Name, "error", err)
+				continue
+			}
+			key := loc.Instance + "/" + loc.Database
+			groups[key] = append(groups[key], backupRun{
+				object: attrs.Name,
+				uri:    fmt.Sprintf("gs://%s/%s", opts.Bucket, attrs.Name),
+				t:      t,
+			})
+		case strings.HasPrefix(filepath.Base(attrs.Name), "manifest-"):
+			reader, err := bucket.Object(attrs.Name).NewReader(ctx)
+			if err != nil {
+				slog.Error("error read manifest", "object", attrs.Name, "error", err)
+				continue
+			}
+			data, err := io.ReadAll(reader)
+			reader.Close()
+			if err != nil {
+				slog.Error("error read manifest", "object", attrs.Name, "error", err)
+				continue
+			}
+			manifest, err := bakstorage.UnmarshalManifest(data)
+			if err != nil {
+				slog.Error("error decode manifest", "object", attrs.Name, "error", err)
+				continue
+			}
+
+			if manifest.Retain && manifest.BaseBackup != "" {
+				protected[manifest.BaseBackup] = true
+			}
+			for _, parent := range manifest.Parents {
+				protected[parent] = true
+			}
+		}
+	}
+
+	return groups, protected, nil
+}
+
+// applyRetentionPolicy returns the set of object names within runs that
+// policy keeps, evaluated as of now.
+func applyRetentionPolicy(runs []backupRun, policy RetentionPolicy, now time.Time) map[string]bool {
+	sorted := make([]backupRun, len(runs))
+	copy(sorted, runs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].t.After(sorted[j].t) })
+
+	keep := make(map[string]bool, len(sorted))
+
+	for i, r := range sorted {
+		if policy.MinAge > 0 && now.Sub(r.t) < policy.MinAge {
+			keep[r.object] = true
+		}
+		if policy.KeepLast > 0 && i < policy.KeepLast {
+			keep[r.object] = true
+		}
+	}
+
+	keepNewestPerPeriod(sorted, keep, policy.KeepDaily, func(t time.Time) string {
+		return t.Format("2006-01-02")
+	})
+	keepNewestPerPeriod(sorted, keep, policy.KeepWeekly, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	})
+	keepNewestPerPeriod(sorted, keep, policy.KeepMonthly, func(t time.Time) string {
+		return t.Format("2006-01")
+	})
+
+	return keep
+}
+
+// keepNewestPerPeriod keeps the newest run (runs must be sorted newest-first)
+// within each of the last n distinct periods identified by periodKey.
+func keepNewestPerPeriod(runs []backupRun, keep map[string]bool, n int, periodKey func(time.Time) string) {
+	if n <= 0 {
+		return
+	}
+
+	seen := map[string]bool{}
+	for _, r := range runs {
+		key := periodKey(r.t)
+		if seen[key] {
+			continue
+		}
+		if len(seen) == n {
+			break
+		}
+		seen[key] = true
+		keep[r.object] = true
+	}
+}
@@ -0,0 +1,53 @@
+package backup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyRetentionPolicyKeepLast(t *testing.T) {
+	now := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	runs := []backupRun{
+		{object: "d1", t: now.AddDate(0, 0, -1)},
+		{object: "d2", t: now.AddDate(0, 0, -2)},
+		{object: "d3", t: now.AddDate(0, 0, -3)},
+	}
+
+	keep := applyRetentionPolicy(runs, RetentionPolicy{KeepLast: 2}, now)
+
+	assert.True(t, keep["d1"])
+	assert.True(t, keep["d2"])
+	assert.False(t, keep["d3"])
+}
+
+func TestApplyRetentionPolicyMinAge(t *testing.T) {
+	now := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	runs := []backupRun{
+		{object: "recent", t: now.Add(-time.Hour)},
+		{object: "old", t: now.AddDate(0, 0, -30)},
+	}
+
+	keep := applyRetentionPolicy(runs, RetentionPolicy{MinAge: 24 * time.Hour}, now)
+
+	assert.True(t, keep["recent"])
+	assert.False(t, keep["old"])
+}
+
+func TestApplyRetentionPolicyKeepDaily(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	runs := []backupRun{
+		{object: "day0-a", t: now},
+		{object: "day0-b", t: now.Add(-time.Hour)},
+		{object: "day1", t: now.AddDate(0, 0, -1)},
+		{object: "day2", t: now.AddDate(0, 0, -2)},
+	}
+
+	keep := applyRetentionPolicy(runs, RetentionPolicy{KeepDaily: 2}, now)
+
+	assert.True(t, keep["day0-a"])
+	assert.False(t, keep["day0-b"])
+	assert.True(t, keep["day1"])
+	assert.False(t, keep["day2"])
+}
@@ -0,0 +1,206 @@
+package cloudsql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ErrBlobNotExist is returned by BlobStore.Open when key does not exist,
+// regardless of which backend is storing it.
+var ErrBlobNotExist = errors.New("cloudsql: blob does not exist")
+
+// BlobStore abstracts the object-storage operations CloudSQL performs
+// directly against a bucket for its own ancillary artifacts (manifests,
+// exported users, exported stats): everything that isn't the SQL dump
+// itself, which sqladmin.Instances.Export/Import always reads and writes as
+// a gs:// URI and so stays on the GCS client regardless of backend; see
+// NewBlobStore.
+type BlobStore interface {
+	// List returns the keys of every object under prefix.
+	List(ctx context.Context, prefix string) ([]string, error)
+	// Open returns a reader for key, or ErrBlobNotExist if it doesn't exist.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Create returns a writer that (over)writes key when closed.
+	Create(ctx context.Context, key string) (io.WriteCloser, error)
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a time-limited URL that grants read access to key
+	// without the caller's own credentials, e.g. to hand a backup off to
+	// another team without granting bucket access.
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// NewBlobStore resolves bucket's scheme into the matching BlobStore and
+// returns it along with the bare bucket name (scheme stripped) to address
+// objects in it. A bucket with no scheme defaults to gs:// and reuses
+// gcsClient, so existing callers are
+// unaffected. gs:// does the same; s3:// builds its own client from the
+// ambient AWS credential chain.
+func NewBlobStore(ctx context.Context, gcsClient *storage.Client, bucket string) (BlobStore, string, error) {
+	scheme, name := splitBucketScheme(bucket)
+	switch scheme {
+	case "", "gs":
+		return &GCSBlobStore{bucket: name, client: gcsClient}, name, nil
+	case "s3":
+		cfg, err := awsconfig.LoadDefaultConfig(ctx)
+		if err != nil {
+			return nil, "", fmt.Errorf("load AWS config for s3 bucket %q: %w", name, err)
+		}
+		return &S3BlobStore{bucket: name, client: s3.NewFromConfig(cfg)}, name, nil
+	default:
+		return nil, "", fmt.Errorf("unsupported storage backend scheme %q", scheme)
+	}
+}
+
+func splitBucketScheme(bucket string) (scheme, name string) {
+	if i := strings.Index(bucket, "://"); i >= 0 {
+		return bucket[:i], bucket[i+len("://"):]
+	}
+	return "", bucket
+}
+
+// GCSBlobStore is the BlobStore backed by Google Cloud Storage.
+type GCSBlobStore struct {
+	bucket string
+	client *storage.Client
+}
+
+func (b *GCSBlobStore) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	it := b.client.Bucket(b.bucket).Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, attrs.Name)
+	}
+	return keys, nil
+}
+
+func (b *GCSBlobStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	r, err := b.client.Bucket(b.bucket).Object(key).NewReader(ctx)
+	if err != nil {
+		if err == storage.ErrObjectNotExist {
+			return nil, ErrBlobNotExist
+		}
+		return nil, err
+	}
+	return r, nil
+}
+
+func (b *GCSBlobStore) Create(ctx context.Context, key string) (io.WriteCloser, error) {
+	return b.client.Bucket(b.bucket).Object(key).NewWriter(ctx), nil
+}
+
+func (b *GCSBlobStore) Delete(ctx context.Context, key string) error {
+	return b.client.Bucket(b.bucket).Object(key).Delete(ctx)
+}
+
+// SignedURL requires the GCS client to be authenticated as a service
+// account (not bare ADC metadata credentials) so the library can sign the
+// URL locally, or iam.serviceAccounts.signBlob permission if it falls back
+// to signing via the IAM Credentials API.
+func (b *GCSBlobStore) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return b.client.Bucket(b.bucket).SignedURL(key, &storage.SignedURLOptions{
+		Method:  "GET",
+		Expires: time.Now().Add(expiry),
+	})
+}
+
+// S3BlobStore is the BlobStore backed by an AWS S3 (or S3-compatible, e.g.
+// MinIO) bucket.
+type S3BlobStore struct {
+	bucket string
+	client *s3.Client
+}
+
+func (b *S3BlobStore) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	paginator := s3.NewListObjectsV2Paginator(b.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(b.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range page.Contents {
+			keys = append(keys, aws.ToString(obj.Key))
+		}
+	}
+	return keys, nil
+}
+
+func (b *S3BlobStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := b.client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(b.bucket), Key: aws.String(key)})
+	if err != nil {
+		var notFound *types.NoSuchKey
+		if errors.As(err, &notFound) {
+			return nil, ErrBlobNotExist
+		}
+		return nil, err
+	}
+	return out.Body, nil
+}
+
+func (b *S3BlobStore) Create(ctx context.Context, key string) (io.WriteCloser, error) {
+	return &s3Writer{ctx: ctx, client: b.client, bucket: b.bucket, key: key}, nil
+}
+
+func (b *S3BlobStore) Delete(ctx context.Context, key string) error {
+	_, err := b.client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(b.bucket), Key: aws.String(key)})
+	return err
+}
+
+func (b *S3BlobStore) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	req, err := s3.NewPresignClient(b.client).PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(expiry))
+	if err != nil {
+		return "", err
+	}
+	return req.URL, nil
+}
+
+// s3Writer buffers writes in memory and uploads them as a single PutObject
+// on Close, matching the buffer-then-put approach pkg/storage.S3Backend
+// already uses: S3 has no native append-style streaming writer the way GCS
+// does.
+type s3Writer struct {
+	ctx    context.Context
+	client *s3.Client
+	bucket string
+	key    string
+	buf    bytes.Buffer
+}
+
+func (w *s3Writer) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *s3Writer) Close() error {
+	_, err := w.client.PutObject(w.ctx, &s3.PutObjectInput{
+		Bucket: aws.String(w.bucket),
+		Key:    aws.String(w.key),
+		Body:   bytes.NewReader(w.buf.Bytes()),
+	})
+	return err
+}
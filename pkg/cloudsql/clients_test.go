@@ -0,0 +1,24 @@
+package cloudsql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestClientConfigClientOptions(t *testing.T) {
+	cfg := clientConfig{}
+	assert.Empty(t, cfg.clientOptions())
+
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "test-token"})
+	WithTokenSource(ts)(&cfg)
+	WithEndpoint("https://sqladmin.example.test")(&cfg)
+	assert.Len(t, cfg.clientOptions(), 2)
+}
+
+func TestLoadJWTKeyFileRejectsMissingFile(t *testing.T) {
+	_, err := LoadJWTKeyFile(context.Background(), "/nonexistent/key.json")
+	assert.Error(t, err)
+}
@@ -2,6 +2,7 @@ package cloudsql
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"io"
@@ -9,19 +10,29 @@ import (
 	"maps"
 	"math"
 	"math/rand"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	bakstorage "github.com/fr12k/cloudsql-exporter/pkg/storage"
 
+	"golang.org/x/sync/errgroup"
+
 	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/sqladmin/v1"
 	"gopkg.in/yaml.v3"
 
 	"cloud.google.com/go/iam"
+	kms "cloud.google.com/go/kms/apiv1"
 	"cloud.google.com/go/secretmanager/apiv1beta2"
 	"cloud.google.com/go/secretmanager/apiv1beta2/secretmanagerpb"
 	"cloud.google.com/go/storage"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/fr12k/cloudsql-exporter/pkg/tracing"
 )
 
 type InstanceID string
@@ -35,23 +46,37 @@ type Instances map[InstanceID]Databases
 
 type CloudSQL struct {
 	ProjectID string
+	// Region is the default GCP region used for resources this package
+	// creates on the caller's behalf (restore instances, password secrets)
+	// when the caller doesn't request a specific location.
+	Region string
 
 	ctx         context.Context
 	sqlAdminSvc *sqladmin.Service
 	storageSvc  *storage.Client
 	secretSvc   *secretmanager.Client
+	kmsSvc      *kms.KeyManagementClient
 }
 
-func NewCloudSQL(ctx context.Context, sqlAdminSvc *sqladmin.Service, storageSvc *storage.Client, secretSvc *secretmanager.Client, projectID string) *CloudSQL {
+func NewCloudSQL(ctx context.Context, sqlAdminSvc *sqladmin.Service, storageSvc *storage.Client, secretSvc *secretmanager.Client, kmsSvc *kms.KeyManagementClient, projectID, region string) *CloudSQL {
 	return &CloudSQL{
 		ProjectID:   projectID,
+		Region:      region,
 		ctx:         ctx,
 		sqlAdminSvc: sqlAdminSvc,
 		storageSvc:  storageSvc,
 		secretSvc:   secretSvc,
+		kmsSvc:      kmsSvc,
 	}
 }
 
+// blobStoreFor resolves bucket (a bare name, defaulting to GCS, or a gs://
+// / s3:// URI) into the matching BlobStore and the bare bucket name to
+// address objects in it.
+func (c *CloudSQL) blobStoreFor(bucket string) (BlobStore, string, error) {
+	return NewBlobStore(c.ctx, c.storageSvc, bucket)
+}
+
 // EnumerateCloudSQLDatabaseInstances enumerates Cloud SQL database instances in the given project.
 func (c *CloudSQL) EnumerateCloudSQLDatabaseInstances(instanceID string) (Instances, error) {
 	slog.Info("Enumerating Cloud SQL instances in project", "projectId", c.ProjectID)
@@ -152,13 +177,23 @@ func (c *CloudSQL) RemoveRoleBindingToGCSBucket(bucketName, role, sqlAdminSvcAcc
 func (c *CloudSQL) ListDatabasesForCloudSQLInstance(instanceID string) (Databases, error) {
 	var databases Databases
 
+	databaseVersion, err := c.InstanceEngineVersion(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	systemDatabases := engineFor(databaseVersion).SystemDatabases()
+	skip := make(map[string]bool, len(systemDatabases))
+	for _, name := range systemDatabases {
+		skip[name] = true
+	}
+
 	list, err := c.sqlAdminSvc.Databases.List(c.ProjectID, instanceID).Do()
 	if err != nil {
 		return nil, err
 	}
 
 	for _, database := range list.Items {
-		if database.Name == "mysql" || database.Name == "postgres" {
+		if skip[database.Name] {
 			slog.Info("Skipping database", "database", database.Name)
 			continue
 		}
@@ -178,17 +213,23 @@ func (c *CloudSQL) ExportUsers(instanceID, database string) ([]*sqladmin.User, e
 }
 
 // ExportCloudSQLUser exports a Cloud SQL users to Google Cloud Storage bucket.
-func (c *CloudSQL) ExportCloudSQLUser(instanceID, bucketName, backupTime string) ([]string, error) {
-	location := fmt.Sprintf("%s/cloudsql/users-%s.txt", instanceID, backupTime)
-	slog.Info("Exporting users for instance", "instance", instanceID, "location", location)
+func (c *CloudSQL) ExportCloudSQLUser(loc bakstorage.Location) ([]string, error) {
+	location := loc.UserLocation()
+	slog.Info("Exporting users for instance", "instance", loc.Instance, "location", location)
 
-	users, err := c.ExportUsers(instanceID, "")
+	users, err := c.ExportUsers(loc.Instance, "")
 	if err != nil {
 		return nil, err
 	}
 
-	bucket := c.storageSvc.Bucket(bucketName)
-	writer := bucket.Object(location).NewWriter(c.ctx)
+	store, _, err := c.blobStoreFor(loc.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := store.Create(c.ctx, location)
+	if err != nil {
+		return nil, err
+	}
 	defer writer.Close()
 
 	userNames := []string{}
@@ -212,25 +253,70 @@ type CloudSQLStatistic struct {
 	TableSizeBytesWithoutIndexes int64  `yaml:"table_size_bytes_without_indexes"`
 	TotalSizeBytes               int64  `yaml:"total_size_bytes"`
 	RowCount                     int64  `yaml:"row_count"`
-}
 
-func (c *CloudSQL) GetCloudSQLStatistic(instanceID, user, password, database string) (map[string]*CloudSQLStatistic, error) {
-	conn := Connection{
-		User: user,
-		//TODO get the password
-		Password: password,
-		Database: database,
-		URL:      fmt.Sprintf("%s:%s:%s", c.ProjectID, "europe-west3", instanceID),
-	}
+	// ContentChecksum is a per-table content hash, only populated when the
+	// caller opts into deep verification (RestoreOptions.DeepVerify,
+	// BackupOptions.DeepVerify). Unlike RowCount, which Postgres reports as
+	// a reltuples estimate refreshed by ANALYZE, this catches rows that
+	// were silently changed without the row count moving. Empty for
+	// engines without a tableContentChecksumQuery implementation.
+	ContentChecksum string `yaml:"content_checksum,omitempty"`
+}
 
-	dbConn, err := conn.Connect()
-	if err != nil {
-		slog.Error("Failed to connect to database", "instance", conn.URL, "database", conn.Database, "error", err)
-		return nil, err
+// engineFromDatabaseVersion maps a sqladmin DatabaseVersion (e.g. "POSTGRES_13",
+// "MYSQL_8_0", "SQLSERVER_2019_STANDARD") to the Engine it is served by.
+func engineFromDatabaseVersion(version string) Engine {
+	switch {
+	case strings.HasPrefix(version, "MYSQL"):
+		return EngineMySQL
+	case strings.HasPrefix(version, "SQLSERVER"):
+		return EngineSQLServer
+	default:
+		return EnginePostgres
 	}
+}
 
-	defer dbConn.Close()
-	statsSQL := `
+// statsQuery returns the row/size statistics query for the given engine.
+// Postgres reports estimated row counts via reltuples (refreshed by ANALYZE);
+// MySQL and SQL Server read the equivalent information_schema catalog views.
+func statsQuery(engine Engine) string {
+	switch engine {
+	case EngineMySQL:
+		return `
+	SELECT
+	CONCAT(table_schema, '.', table_name) AS full_table_name,
+	data_length + index_length AS table_size_bytes,
+	data_length AS table_size_bytes_without_indexes,
+	data_length + index_length AS total_size_bytes,
+	table_rows AS row_count
+FROM
+	information_schema.tables
+WHERE
+	table_schema NOT IN ('mysql', 'information_schema', 'performance_schema', 'sys')
+ORDER BY
+	table_schema,
+	table_name;`
+	case EngineSQLServer:
+		return `
+	SELECT
+	s.name + '.' + t.name AS full_table_name,
+	SUM(a.total_pages) * 8 * 1024 AS table_size_bytes,
+	SUM(a.used_pages) * 8 * 1024 AS table_size_bytes_without_indexes,
+	SUM(a.total_pages) * 8 * 1024 AS total_size_bytes,
+	MAX(p.rows) AS row_count
+FROM
+	sys.tables t
+JOIN sys.schemas s ON t.schema_id = s.schema_id
+JOIN sys.partitions p ON t.object_id = p.object_id AND p.index_id IN (0, 1)
+JOIN sys.allocation_units a ON p.partition_id = a.container_id
+GROUP BY
+	s.name,
+	t.name
+ORDER BY
+	s.name,
+	t.name;`
+	default:
+		return `
 	SELECT
 	schemaname || '.' || tablename AS full_table_name,
 	pg_table_size(schemaname || '.' || tablename) AS table_size_bytes,
@@ -246,15 +332,97 @@ WHERE
 ORDER BY
 	schemaname,
 	tablename;`
+	}
+}
+
+// tableContentChecksumQuery returns the per-table content hash query for
+// engine, or "" if this engine has no content-checksum support yet (SQL
+// Server, which has no single built-in equivalent). fullTableName is
+// schema-qualified as returned by statsQuery (e.g. "public.accounts").
+func tableContentChecksumQuery(engine Engine, fullTableName string) string {
+	switch engine {
+	case EngineMySQL:
+		return fmt.Sprintf("CHECKSUM TABLE %s", fullTableName)
+	case EngineSQLServer:
+		return ""
+	default:
+		return fmt.Sprintf(`SELECT md5(string_agg(t::text, '' ORDER BY ctid)) FROM %s t`, fullTableName)
+	}
+}
+
+// contentChecksum runs engine's content-checksum query for fullTableName and
+// returns the resulting hash, or "" if the engine has none.
+func contentChecksum(dbConn *sql.DB, engine Engine, fullTableName string) (string, error) {
+	query := tableContentChecksumQuery(engine, fullTableName)
+	if query == "" {
+		return "", nil
+	}
+
+	row := dbConn.QueryRow(query)
+	switch engine {
+	case EngineMySQL:
+		// CHECKSUM TABLE returns (Table, Checksum) rather than a scalar.
+		var table string
+		var checksum sql.NullInt64
+		if err := row.Scan(&table, &checksum); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d", checksum.Int64), nil
+	default:
+		var checksum sql.NullString
+		if err := row.Scan(&checksum); err != nil {
+			return "", err
+		}
+		return checksum.String, nil
+	}
+}
+
+// InstanceEngineVersion returns the sqladmin DatabaseVersion (e.g.
+// "POSTGRES_13") reported for instanceID, so callers can record it in the
+// backup manifest without duplicating the Instances.Get call.
+func (c *CloudSQL) InstanceEngineVersion(instanceID string) (string, error) {
+	instance, err := c.sqlAdminSvc.Instances.Get(c.ProjectID, instanceID).Do()
+	if err != nil {
+		return "", err
+	}
+	return instance.DatabaseVersion, nil
+}
 
-	_, err = dbConn.Exec("ANALYZE VERBOSE;")
+// GetCloudSQLStatistic collects per-table row/size statistics for database.
+// When deepVerify is set, it additionally computes each table's
+// ContentChecksum, running up to parallelism checksum queries concurrently
+// (parallelism <= 0 behaves as 1).
+func (c *CloudSQL) GetCloudSQLStatistic(instanceID, user, password, database string, deepVerify bool, parallelism int) (map[string]*CloudSQLStatistic, error) {
+	instance, err := c.sqlAdminSvc.Instances.Get(c.ProjectID, instanceID).Do()
+	if err != nil {
+		return nil, err
+	}
+	engine := engineFromDatabaseVersion(instance.DatabaseVersion)
+
+	conn := Connection{
+		Engine: engine,
+		User:   user,
+		//TODO get the password
+		Password: password,
+		Database: database,
+		URL:      fmt.Sprintf("%s:%s:%s", c.ProjectID, instance.Region, instanceID),
+	}
 
+	dbConn, err := conn.Connect()
 	if err != nil {
-		slog.Error("Failed to execute analyze query", "instance", conn.URL, "database", conn.Database, "error", err)
+		slog.Error("Failed to connect to database", "instance", conn.URL, "database", conn.Database, "error", err)
 		return nil, err
 	}
+	defer dbConn.Close()
+
+	if engine == EnginePostgres {
+		if _, err := dbConn.Exec("ANALYZE VERBOSE;"); err != nil {
+			slog.Error("Failed to execute analyze query", "instance", conn.URL, "database", conn.Database, "error", err)
+			return nil, err
+		}
+	}
 
-	rows, err := dbConn.Query(statsSQL)
+	rows, err := dbConn.Query(statsQuery(engine))
 	if err != nil {
 		slog.Error("Failed to execute query", "instance", conn.URL, "database", conn.Database, "error", err)
 		return nil, err
@@ -278,23 +446,46 @@ ORDER BY
 		return nil, err
 	}
 
+	if deepVerify {
+		if parallelism <= 0 {
+			parallelism = 1
+		}
+		g := new(errgroup.Group)
+		g.SetLimit(parallelism)
+		for _, stat := range stats {
+			stat := stat
+			g.Go(func() error {
+				checksum, err := contentChecksum(dbConn, engine, stat.FullTableName)
+				if err != nil {
+					slog.Error("Failed to compute content checksum", "table", stat.FullTableName, "error", err)
+					return err
+				}
+				stat.ContentChecksum = checksum
+				return nil
+			})
+		}
+		if err := g.Wait(); err != nil {
+			return nil, err
+		}
+	}
+
 	return stats, nil
 }
 
 // ExportCloudSQLStatistics exports statistics like tables and size Google Cloud Storage bucket.
-func (c *CloudSQL) ExportCloudSQLStatistics(databases []string, instanceID, bucketName, backupTime string, user string, password string) (map[string]*CloudSQLStatistic, error) {
+func (c *CloudSQL) ExportCloudSQLStatistics(loc bakstorage.Location, databases []string, user string, password string, deepVerify bool, statsParallelism int) (map[string]*CloudSQLStatistic, error) {
 	stats := make(map[string]*CloudSQLStatistic)
 
 	for _, database := range databases {
-		dbStats, err := c.GetCloudSQLStatistic(instanceID, user, password, database)
+		dbStats, err := c.GetCloudSQLStatistic(loc.Instance, user, password, database, deepVerify, statsParallelism)
 		if err != nil {
 			return nil, err
 		}
 
-		location := fmt.Sprintf("%s/cloudsql/stats-%s-%s.yaml", instanceID, database, backupTime)
-		slog.Info("Exporting statistics for instance", "instance", instanceID, "location", location)
+		location := loc.StatsLocation(database)
+		slog.Info("Exporting statistics for instance", "instance", loc.Instance, "location", location)
 
-		bucket := c.storageSvc.Bucket(bucketName)
+		bucket := c.storageSvc.Bucket(loc.Bucket)
 		writer := bucket.Object(location).NewWriter(c.ctx)
 		defer writer.Close()
 
@@ -309,32 +500,46 @@ func (c *CloudSQL) ExportCloudSQLStatistics(databases []string, instanceID, buck
 }
 
 // ExportCloudSQLDatabase exports a Cloud SQL database to a Google Cloud Storage bucket.
-func (c *CloudSQL) ExportCloudSQLDatabase(databases []string, instanceID, bucketName, objectName string) ([]string, error) {
+// When since is zero, it runs the full sqladmin instance export used for base
+// backups. Otherwise it emits an incremental export per database containing
+// only the rows that changed after since, per the per-table cursor
+// configuration in incrementalManifestLocation, and returns the updated
+// high-water marks so the caller can persist them in the backup's manifest.
+func (c *CloudSQL) ExportCloudSQLDatabase(loc bakstorage.Location, databases []string, user, password string, since time.Time) ([]string, map[string]bakstorage.TableCursor, error) {
+	if since.IsZero() {
+		locations, err := c.exportCloudSQLDatabaseFull(loc, databases)
+		return locations, nil, err
+	}
+	return c.exportCloudSQLDatabaseIncremental(loc, databases, user, password, since)
+}
+
+func (c *CloudSQL) exportCloudSQLDatabaseFull(loc bakstorage.Location, databases []string) ([]string, error) {
+	databaseVersion, err := c.InstanceEngineVersion(loc.Instance)
+	if err != nil {
+		return nil, err
+	}
+	engine := engineFor(databaseVersion)
+
+	dbLoc := loc
+	dbLoc.Suffix = engine.ObjectSuffix(loc.Compression)
+
 	locations := make([]string, 0)
 	for _, database := range databases {
-		objectName := fmt.Sprintf("%s-%s", database, objectName)
-		//TODO make this configurable
-
-		location := fmt.Sprintf("gs://%s/%s/cloudsql/%s", bucketName, instanceID, objectName)
+		location := dbLoc.DatabaseLocation(database)
 
 		locations = append(locations, location)
-		slog.Info("Exporting database for instance", "database", database, "instance", instanceID, "location", location)
+		slog.Info("Exporting database for instance", "database", database, "instance", loc.Instance, "location", location)
 
 		req := &sqladmin.InstancesExportRequest{
-			ExportContext: &sqladmin.ExportContext{
-				FileType:  "SQL",
-				Kind:      "sql#exportContext",
-				Databases: []string{database},
-				Uri:       location,
-			},
+			ExportContext: engine.ExportRequest(database, location),
 		}
 
-		op, err := c.sqlAdminSvc.Instances.Export(c.ProjectID, instanceID, req).Do()
+		op, err := c.sqlAdminSvc.Instances.Export(c.ProjectID, loc.Instance, req).Do()
 		if err != nil {
 			return nil, err
 		}
 
-		err = c.WaitForSQLOperation(time.Minute*1, op)
+		err = c.WaitForSQLOperation(DefaultWaitConfig, op)
 		if err != nil {
 			return nil, err
 		}
@@ -343,34 +548,386 @@ func (c *CloudSQL) ExportCloudSQLDatabase(databases []string, instanceID, bucket
 	return locations, nil
 }
 
-func (c *CloudSQL) WaitForSQLOperation(timeout time.Duration, op *sqladmin.Operation) error {
-	if op == nil {
-		return errors.New("got nil op")
+// incrementalManifestLocation is the per-instance, user-authored config
+// listing which column to use as the incremental cursor for each table (an
+// updated_at column or a monotonically increasing primary key).
+func incrementalManifestLocation(loc bakstorage.Location) string {
+	return fmt.Sprintf("%sincremental.yaml", loc.Path)
+}
+
+func (c *CloudSQL) loadIncrementalCursorConfig(loc bakstorage.Location) (map[string]string, error) {
+	reader, err := c.storageSvc.Bucket(loc.Bucket).Object(incrementalManifestLocation(loc)).NewReader(c.ctx)
+	if err != nil {
+		if err == storage.ErrObjectNotExist {
+			return map[string]string{}, nil
+		}
+		return nil, err
 	}
+	defer reader.Close()
 
-	for {
-		select {
-		case <-c.ctx.Done():
-			return errors.New("timeout reached")
-		default:
-			time.Sleep(timeout)
-			op, err := c.sqlAdminSvc.Operations.Get(c.ProjectID, op.Name).Do()
+	cursorColumns := map[string]string{}
+	if err := yaml.NewDecoder(reader).Decode(&cursorColumns); err != nil {
+		return nil, err
+	}
+	return cursorColumns, nil
+}
+
+// incrementalCursorStateLocation is the machine-written record of each
+// table's last high-water mark, kept separate from incrementalManifestLocation
+// (the user-authored column config) so writing it back after every run can't
+// clobber the user's own edits to that file.
+func incrementalCursorStateLocation(loc bakstorage.Location) string {
+	return fmt.Sprintf("%scursor-state.yaml", loc.Path)
+}
+
+func (c *CloudSQL) loadIncrementalCursorState(loc bakstorage.Location) (map[string]bakstorage.TableCursor, error) {
+	reader, err := c.storageSvc.Bucket(loc.Bucket).Object(incrementalCursorStateLocation(loc)).NewReader(c.ctx)
+	if err != nil {
+		if err == storage.ErrObjectNotExist {
+			return map[string]bakstorage.TableCursor{}, nil
+		}
+		return nil, err
+	}
+	defer reader.Close()
+
+	state := map[string]bakstorage.TableCursor{}
+	if err := yaml.NewDecoder(reader).Decode(&state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (c *CloudSQL) saveIncrementalCursorState(loc bakstorage.Location, state map[string]bakstorage.TableCursor) error {
+	writer := c.storageSvc.Bucket(loc.Bucket).Object(incrementalCursorStateLocation(loc)).NewWriter(c.ctx)
+	if err := yaml.NewEncoder(writer).Encode(state); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+// parseCursorValue inverts formatCursorValue, returning the typed value to
+// bind as a table's ">" query parameter: an RFC3339 timestamp parses to
+// time.Time, a bare integer to int64, and anything else is bound as-is.
+func parseCursorValue(s string) any {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t
+	}
+	return s
+}
+
+func (c *CloudSQL) exportCloudSQLDatabaseIncremental(loc bakstorage.Location, databases []string, user, password string, since time.Time) ([]string, map[string]bakstorage.TableCursor, error) {
+	cursorColumns, err := c.loadIncrementalCursorConfig(loc)
+	if err != nil {
+		return nil, nil, err
+	}
+	cursorState, err := c.loadIncrementalCursorState(loc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	locations := make([]string, 0)
+	cursors := make(map[string]bakstorage.TableCursor)
+
+	for _, database := range databases {
+		incrLoc := loc
+		incrLoc.Kind = bakstorage.KindIncremental
+		location := incrLoc.DatabaseLocation(database)
+		locations = append(locations, location)
+		slog.Info("Exporting incremental database for instance", "database", database, "instance", loc.Instance, "location", location, "since", since)
+
+		instance, err := c.sqlAdminSvc.Instances.Get(c.ProjectID, loc.Instance).Do()
+		if err != nil {
+			return nil, nil, err
+		}
+		conn := Connection{
+			Engine:   engineFromDatabaseVersion(instance.DatabaseVersion),
+			User:     user,
+			Password: password,
+			Database: database,
+			URL:      fmt.Sprintf("%s:%s:%s", c.ProjectID, instance.Region, loc.Instance),
+		}
+
+		dbConn, err := conn.Connect()
+		if err != nil {
+			slog.Error("Failed to connect to database", "instance", conn.URL, "database", database, "error", err)
+			return nil, nil, err
+		}
+
+		bucket := c.storageSvc.Bucket(loc.Bucket)
+		writer := bucket.Object(location).NewWriter(c.ctx)
+
+		for table, column := range cursorColumns {
+			key := fmt.Sprintf("%s.%s", database, table)
+			var bound any = since
+			if prior, ok := cursorState[key]; ok && prior.HighWaterMark != "" {
+				bound = parseCursorValue(prior.HighWaterMark)
+			}
+
+			query := fmt.Sprintf("SELECT * FROM %s WHERE %s > %s ORDER BY %s", table, column, cursorPlaceholder(conn.Engine), column)
+			rows, err := dbConn.Query(query, bound)
 			if err != nil {
-				return err
+				writer.Close()
+				dbConn.Close()
+				return nil, nil, fmt.Errorf("query incremental rows for table %s: %w", table, err)
 			}
-			if op.Error != nil {
-				var errors []string
-				for _, e := range op.Error.Errors {
-					errors = append(errors, e.Message)
+
+			cols, err := rows.Columns()
+			if err != nil {
+				rows.Close()
+				writer.Close()
+				dbConn.Close()
+				return nil, nil, err
+			}
+			cursorIdx := -1
+			for i, c := range cols {
+				if strings.EqualFold(c, column) {
+					cursorIdx = i
+					break
 				}
-				return fmt.Errorf("operation failed: %s", errors)
 			}
-			if op.Status == "DONE" {
-				return nil
+
+			highWaterMark := bound
+			for rows.Next() {
+				raw, err := scanRowValues(rows, len(cols))
+				if err != nil {
+					rows.Close()
+					writer.Close()
+					dbConn.Close()
+					return nil, nil, err
+				}
+				if _, err := fmt.Fprintf(writer, "INSERT INTO %s VALUES (%s);\n", table, joinSQLLiterals(raw)); err != nil {
+					rows.Close()
+					writer.Close()
+					dbConn.Close()
+					return nil, nil, err
+				}
+				if cursorIdx >= 0 && compareCursorValues(raw[cursorIdx], highWaterMark) > 0 {
+					highWaterMark = raw[cursorIdx]
+				}
+			}
+			rows.Close()
+
+			cursors[key] = bakstorage.TableCursor{
+				Column:        column,
+				HighWaterMark: formatCursorValue(highWaterMark),
+			}
+		}
+
+		if err := writer.Close(); err != nil {
+			dbConn.Close()
+			return nil, nil, err
+		}
+		dbConn.Close()
+	}
+
+	if err := c.saveIncrementalCursorState(loc, cursors); err != nil {
+		return nil, nil, err
+	}
+
+	return locations, cursors, nil
+}
+
+// cursorPlaceholder returns the bound-parameter placeholder engine's driver
+// expects for the first (and here, only) argument of a parameterized query:
+// pgx requires "$1", the MySQL driver requires "?", and the SQL Server
+// driver requires "@p1".
+func cursorPlaceholder(engine Engine) string {
+	switch engine {
+	case EngineMySQL:
+		return "?"
+	case EngineSQLServer:
+		return "@p1"
+	default:
+		return "$1"
+	}
+}
+
+// scanRowValues scans the current row (rows.Next() must already have
+// returned true) into a slice of driver values.
+func scanRowValues(rows *sql.Rows, numCols int) ([]any, error) {
+	raw := make([]any, numCols)
+	ptrs := make([]any, numCols)
+	for i := range raw {
+		ptrs[i] = &raw[i]
+	}
+	if err := rows.Scan(ptrs...); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// joinSQLLiterals renders raw as a comma separated list of SQL literals
+// suitable for an INSERT ... VALUES (...) statement.
+func joinSQLLiterals(raw []any) string {
+	parts := make([]string, len(raw))
+	for i, v := range raw {
+		parts[i] = sqlLiteral(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sqlLiteral renders v, as scanned from a database/sql driver value, as a
+// SQL literal. time.Time and bool need engine-agnostic literal forms rather
+// than Go's %v (e.g. "2024-04-04 15:29:57 +0000 UTC" is not valid SQL).
+func sqlLiteral(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return "'" + strings.ReplaceAll(string(val), "'", "''") + "'"
+	case string:
+		return "'" + strings.ReplaceAll(val, "'", "''") + "'"
+	case time.Time:
+		return "'" + val.UTC().Format("2006-01-02 15:04:05.999999") + "'"
+	case bool:
+		if val {
+			return "TRUE"
+		}
+		return "FALSE"
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+// compareCursorValues returns 1, -1 or 0 as a sorts after, before or equal
+// to b, for cursor columns
+// that are either an updated_at timestamp or a monotonically increasing
+// integer primary key (the two cases incrementalManifestLocation's config
+// documents). Other types fall back to comparing their %v representation,
+// which is enough to detect "unchanged" but not a meaningful ordering.
+func compareCursorValues(a, b any) int {
+	switch av := a.(type) {
+	case time.Time:
+		if bv, ok := b.(time.Time); ok {
+			switch {
+			case av.After(bv):
+				return 1
+			case av.Before(bv):
+				return -1
+			default:
+				return 0
+			}
+		}
+	case int64:
+		if bv, ok := b.(int64); ok {
+			switch {
+			case av > bv:
+				return 1
+			case av < bv:
+				return -1
+			default:
+				return 0
 			}
 		}
 	}
+	return strings.Compare(fmt.Sprintf("%v", a), fmt.Sprintf("%v", b))
+}
+
+// formatCursorValue renders a cursor column's high-water-mark value for
+// storage in TableCursor, matching the RFC3339 format previously used for
+// the initial "since" watermark.
+func formatCursorValue(v any) string {
+	switch val := v.(type) {
+	case time.Time:
+		return val.Format(time.RFC3339)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case []byte:
+		return string(val)
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
 
+// GetOperation fetches the current state of a previously issued sqladmin
+// operation by name with a single, non-blocking call.
+// WaitForSQLOperation polls this in a loop; callers that just want a status
+// probe (e.g. GET /operations/{id}) can call it directly.
+func (c *CloudSQL) GetOperation(name string) (*sqladmin.Operation, error) {
+	return c.sqlAdminSvc.Operations.Get(c.ProjectID, name).Do()
+}
+
+// WaitConfig tunes WaitForSQLOperation's backoff: it polls every
+// InitialInterval, backing off by Multiplier (with +/-20% jitter on each
+// sleep so concurrent callers don't hammer the API in lockstep) up to
+// MaxInterval, and gives up once MaxElapsed has passed.
+type WaitConfig struct {
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Multiplier      float64
+	MaxElapsed      time.Duration
+}
+
+// DefaultWaitConfig suits long-running operations (instance insert,
+// import/export, clone) that can legitimately take many minutes.
+var DefaultWaitConfig = WaitConfig{
+	InitialInterval: 2 * time.Second,
+	MaxInterval:     30 * time.Second,
+	Multiplier:      1.5,
+	MaxElapsed:      30 * time.Minute,
+}
+
+// ShortWaitConfig suits short-lived operations (user/database
+// create/delete) that typically finish in a few seconds, so it polls
+// eagerly and gives up sooner.
+var ShortWaitConfig = WaitConfig{
+	InitialInterval: 2 * time.Second,
+	MaxInterval:     10 * time.Second,
+	Multiplier:      1.5,
+	MaxElapsed:      5 * time.Minute,
+}
+
+// WaitForSQLOperation polls op until it reports DONE, failure, or cfg's
+// MaxElapsed deadline passes, backing off between polls per cfg.
+func (c *CloudSQL) WaitForSQLOperation(cfg WaitConfig, op *sqladmin.Operation) error {
+	if op == nil {
+		return errors.New("got nil op")
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, cfg.MaxElapsed)
+	defer cancel()
+
+	start := time.Now()
+	interval := cfg.InitialInterval
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("operation %s (%s) did not complete within %s: %w", op.Name, op.OperationType, time.Since(start), ctx.Err())
+		case <-time.After(jitter(interval)):
+		}
+
+		current, err := c.GetOperation(op.Name)
+		if err != nil {
+			return err
+		}
+		if current.Error != nil {
+			var msgs []string
+			for _, e := range current.Error.Errors {
+				msgs = append(msgs, e.Message)
+			}
+			return fmt.Errorf("operation %s (%s) failed after %s: %s", current.Name, current.OperationType, time.Since(start), msgs)
+		}
+		if current.Status == "DONE" {
+			return nil
+		}
+
+		interval = time.Duration(float64(interval) * cfg.Multiplier)
+		if interval > cfg.MaxInterval {
+			interval = cfg.MaxInterval
+		}
+	}
+}
+
+// jitter returns d scaled by a random factor in [0.8, 1.2], so many
+// concurrent pollers spread out instead of retrying in lockstep.
+func jitter(d time.Duration) time.Duration {
+	delta := 0.2 * float64(d)
+	return time.Duration(float64(d) - delta + rand.Float64()*2*delta)
 }
 
 func generatePassword(length int) string {
@@ -392,18 +949,116 @@ func generatePassword(length int) string {
 }
 
 type RestoreOptions struct {
+	// Bucket is the bucket Restore reads its manifest, exported user and
+	// exported stats files from. A bare name defaults to GCS; a gs:// or
+	// s3:// URI picks the matching BlobStore explicitly. PointInTime and the
+	// legacy bucket IAM bindings Restore grants the Cloud SQL service
+	// account still require a GCS bucket name regardless of scheme, since
+	// object-generation listing and bucket IAM are GCS-only concepts.
 	Bucket   string
 	Project  string
 	Instance string
 	File     string
 	User     string
 
-	StoreSecret   bool
+	// Password connects to Instance (the source, not the restore instance)
+	// so Restore can run Verify against it. Empty skips Verify and Cleanup
+	// entirely, leaving today's stats-file-only validation as the only
+	// integrity check.
+	Password string
+
+	StoreSecret bool
+
+	// Cleanup, when set alongside Password, deletes the ephemeral restore
+	// instance once Verify reports every table matched the source. Restore
+	// instances are left running (for inspection) whenever Verify fails,
+	// doesn't run, or Cleanup is unset.
+	Cleanup bool
+
+	// SecretReplication controls how the password secret StoreSecret saves
+	// is replicated. The zero value replicates to a single user-managed
+	// replica in CloudSQL.Region, matching the previous hard-coded
+	// behavior.
+	SecretReplication SecretReplication
+
+	// Timestamp, when set (RFC3339), switches Restore into point-in-time
+	// recovery mode: instead of importing the SQL dump named by File,
+	// Restore locates the newest full backup for File's database at or
+	// before Timestamp and imports that into the restore instance. It then
+	// clones Instance (the source) up to Timestamp with the Cloud SQL clone
+	// API into restore-pitr-<Instance>, deletes the import-based restore
+	// instance, and returns the clone; the imported dump itself is not
+	// rolled forward. Empty keeps today's single-dump-replay behavior.
+	Timestamp string
+
+	// PointInTime, when set (RFC3339), switches Restore into GCS-generation
+	// based point-in-time recovery instead of Cloud SQL's native clone API:
+	// the newest full-dump object generation under File's cloudsql/ prefix
+	// created at or before PointInTime is imported, then every binlogs/
+	// incremental dump generation up to PointInTime is replayed against the
+	// restore instance in order. This gives PITR semantics on top of the
+	// plain gzip SQL dumps without requiring native PITR support, so it
+	// takes precedence over Timestamp when both are set.
+	PointInTime string
+
+	// DeepVerify, when set, recomputes a per-table content checksum on the
+	// restore instance and compares it against the checksum recorded by
+	// ExportCloudSQLStatistics, in addition to the row-count comparison
+	// that always runs. Row counts alone can match while data silently
+	// differs, since Postgres reports them as a reltuples estimate
+	// refreshed by ANALYZE. Off by default because it re-reads every row
+	// of every table.
+	DeepVerify bool
+	// StatsParallelism bounds how many tables' checksums DeepVerify
+	// computes concurrently. <= 0 behaves as 1.
+	StatsParallelism int
+
+	// KMSKey, when set, tells Restore that File was encrypted by
+	// EncryptExportedObject with this Cloud KMS key (a full resource name)
+	// and must be unwrapped and decrypted into a temporary plaintext object
+	// before Cloud SQL's native Instances.Import can read it.
+	KMSKey string
 
 	Version string
 }
 
-func (c *CloudSQL) savePassword(password string, dbInstance string) error {
+// SecretReplication configures Secret Manager replication for a password
+// secret. Automatic selects Secret Manager's automatic (global) replication
+// policy; otherwise the secret is replicated to the user-managed locations
+// listed in Locations, falling back to a single replica in CloudSQL.Region
+// when Locations is empty.
+type SecretReplication struct {
+	Automatic bool
+	Locations []string
+}
+
+func (r SecretReplication) toProto(defaultRegion string) *secretmanagerpb.Replication {
+	if r.Automatic {
+		return &secretmanagerpb.Replication{
+			Replication: &secretmanagerpb.Replication_Automatic_{
+				Automatic: &secretmanagerpb.Replication_Automatic{},
+			},
+		}
+	}
+
+	locations := r.Locations
+	if len(locations) == 0 {
+		locations = []string{defaultRegion}
+	}
+	replicas := make([]*secretmanagerpb.Replication_UserManaged_Replica, len(locations))
+	for i, location := range locations {
+		replicas[i] = &secretmanagerpb.Replication_UserManaged_Replica{Location: location}
+	}
+	return &secretmanagerpb.Replication{
+		Replication: &secretmanagerpb.Replication_UserManaged_{
+			UserManaged: &secretmanagerpb.Replication_UserManaged{
+				Replicas: replicas,
+			},
+		},
+	}
+}
+
+func (c *CloudSQL) savePassword(password string, dbInstance string, replication SecretReplication) error {
 	// Create the secret
 	secret, err := c.secretSvc.GetSecret(c.ctx, &secretmanagerpb.GetSecretRequest{
 		Name: fmt.Sprintf("projects/%s/secrets/%s", c.ProjectID, strings.ToUpper(dbInstance)),
@@ -429,18 +1084,8 @@ func (c *CloudSQL) savePassword(password string, dbInstance string) error {
 			Parent:   fmt.Sprintf("projects/%s", c.ProjectID),
 			SecretId: strings.ToUpper(dbInstance),
 			Secret: &secretmanagerpb.Secret{
-				Replication: &secretmanagerpb.Replication{
-					Replication: &secretmanagerpb.Replication_UserManaged_{
-						UserManaged: &secretmanagerpb.Replication_UserManaged{
-							Replicas: []*secretmanagerpb.Replication_UserManaged_Replica{
-								{
-									Location: "europe-west3",
-								},
-							},
-						},
-					},
-				},
-				Name: strings.ToUpper(dbInstance),
+				Replication: replication.toProto(c.Region),
+				Name:        strings.ToUpper(dbInstance),
 			},
 		})
 
@@ -462,14 +1107,363 @@ func (c *CloudSQL) savePassword(password string, dbInstance string) error {
 	return nil
 }
 
+// loadManifest reads and parses the backup manifest for loc, returning
+// ok=false (not an error) if this backup predates manifests and has none.
+func (c *CloudSQL) loadManifest(loc bakstorage.Location) (manifest bakstorage.Manifest, ok bool, err error) {
+	store, _, err := c.blobStoreFor(loc.Bucket)
+	if err != nil {
+		return bakstorage.Manifest{}, false, err
+	}
+	reader, err := store.Open(c.ctx, loc.ManifestLocation())
+	if err != nil {
+		if errors.Is(err, ErrBlobNotExist) {
+			return bakstorage.Manifest{}, false, nil
+		}
+		return bakstorage.Manifest{}, false, err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return bakstorage.Manifest{}, false, err
+	}
+	manifest, err = bakstorage.UnmarshalManifest(data)
+	if err != nil {
+		return bakstorage.Manifest{}, false, err
+	}
+	return manifest, true, nil
+}
+
+// verifyBackupChecksum re-hashes the object at fileURI and compares it
+// against the digest recorded in loc's manifest, rejecting a restore that
+// would import a corrupted or tampered archive. Backups without a manifest
+// object, or without a recorded checksum for fileURI (taken without
+// --checksum), are not verified and return nil.
+func (c *CloudSQL) verifyBackupChecksum(loc bakstorage.Location, fileURI string) error {
+	manifest, ok, err := c.loadManifest(loc)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	checksum, ok := manifest.FindObjectChecksum(fileURI)
+	if !ok {
+		return nil
+	}
+
+	store, _, err := c.blobStoreFor(loc.Bucket)
+	if err != nil {
+		return err
+	}
+	key := strings.TrimPrefix(fileURI, fmt.Sprintf("gs://%s/", loc.Bucket))
+	objReader, err := store.Open(c.ctx, key)
+	if err != nil {
+		return err
+	}
+	defer objReader.Close()
+
+	digest, err := bakstorage.DigestReader(objReader)
+	if err != nil {
+		return err
+	}
+	if digest.SHA256 != checksum.SHA256 {
+		return fmt.Errorf("checksum mismatch for %s: expected sha256 %s, got %s", fileURI, checksum.SHA256, digest.SHA256)
+	}
+	return nil
+}
+
+// findClosestBackupBefore scans bucket for the newest full backup object of
+// database under instance's prefix whose timestamp does not exceed target,
+// so Restore can pick a base dump to import before running a follow-up
+// point-in-time recovery up to RestoreOptions.Timestamp. Incremental exports
+// are skipped: Instances.Import replays a single dump, not a cursor chain.
+func (c *CloudSQL) findClosestBackupBefore(bucket, instance, database string, target time.Time) (string, error) {
+	prefix := fmt.Sprintf("%s/cloudsql/", instance)
+	it := c.storageSvc.Bucket(bucket).Objects(c.ctx, &storage.Query{Prefix: prefix})
+
+	var best string
+	var bestTime time.Time
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if !strings.HasSuffix(attrs.Name, ".sql") && !strings.HasSuffix(attrs.Name, ".sql.gz") && !strings.HasSuffix(attrs.Name, ".bak") {
+			continue
+		}
+
+		uri := fmt.Sprintf("gs://%s/%s", bucket, attrs.Name)
+		loc := bakstorage.NewLocation(uri)
+		if loc.Database != database || loc.Kind != bakstorage.KindFull {
+			continue
+		}
+
+		t, err := time.Parse("20060102T150405", loc.Time)
+		if err != nil || t.After(target) {
+			continue
+		}
+		if best == "" || t.After(bestTime) {
+			best, bestTime = uri, t
+		}
+	}
+
+	if best == "" {
+		return "", fmt.Errorf("no full backup found for database %q before %s", database, target.Format(time.RFC3339))
+	}
+	return best, nil
+}
+
+// resolvePointInTimeBackups finds the backup chain needed to bring database
+// back to its state as of target, using GCS object generations instead of
+// Cloud SQL's native PITR (which not every engine/tier supports). Unlike
+// findClosestBackupBefore, which parses the timestamp embedded in each full
+// dump's file name, this walks actual GCS object generations via
+// storage.Query{Versions: true}: it picks the newest full-dump object
+// generation created at or before target, then every generation of every
+// object under the sibling binlogs/ prefix created after that full dump and
+// at or before target, oldest first.
+func (c *CloudSQL) resolvePointInTimeBackups(bucket, instance, database string, target time.Time) (full string, incrementals []string, err error) {
+	fullPrefix := fmt.Sprintf("%s/cloudsql/", instance)
+	fullURI, fullCreated, err := c.newestGenerationBefore(bucket, fullPrefix, database, target)
+	if err != nil {
+		return "", nil, err
+	}
+
+	binlogPrefix := fmt.Sprintf("%s/cloudsql/binlogs/", instance)
+	it := c.storageSvc.Bucket(bucket).Objects(c.ctx, &storage.Query{Prefix: binlogPrefix, Versions: true})
+
+	type generation struct {
+		uri     string
+		created time.Time
+	}
+	var gens []generation
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		if bakstorage.NewLocation(fmt.Sprintf("gs://%s/%s", bucket, attrs.Name)).Database != database {
+			continue
+		}
+		if attrs.Created.Before(fullCreated) || attrs.Created.After(target) {
+			continue
+		}
+		gens = append(gens, generation{
+			uri:     fmt.Sprintf("gs://%s/%s#%d", bucket, attrs.Name, attrs.Generation),
+			created: attrs.Created,
+		})
+	}
+	sort.Slice(gens, func(i, j int) bool { return gens[i].created.Before(gens[j].created) })
+
+	incrementals = make([]string, len(gens))
+	for i, g := range gens {
+		incrementals[i] = g.uri
+	}
+	return fullURI, incrementals, nil
+}
+
+// newestGenerationBefore returns the gs:// URI and creation time of the
+// newest full-dump object generation under prefix for database, created at
+// or before target.
+func (c *CloudSQL) newestGenerationBefore(bucket, prefix, database string, target time.Time) (string, time.Time, error) {
+	it := c.storageSvc.Bucket(bucket).Objects(c.ctx, &storage.Query{Prefix: prefix, Versions: true})
+
+	var best string
+	var bestCreated time.Time
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return "", time.Time{}, err
+		}
+
+		if !strings.HasSuffix(attrs.Name, ".sql") && !strings.HasSuffix(attrs.Name, ".sql.gz") && !strings.HasSuffix(attrs.Name, ".bak") {
+			continue
+		}
+
+		uri := fmt.Sprintf("gs://%s/%s", bucket, attrs.Name)
+		loc := bakstorage.NewLocation(uri)
+		if loc.Database != database || loc.Kind != bakstorage.KindFull {
+			continue
+		}
+		if attrs.Created.After(target) {
+			continue
+		}
+		if best == "" || attrs.Created.After(bestCreated) {
+			best, bestCreated = uri, attrs.Created
+		}
+	}
+
+	if best == "" {
+		return "", time.Time{}, fmt.Errorf("no full backup generation found for database %q before %s", database, target.Format(time.RFC3339))
+	}
+	return best, bestCreated, nil
+}
+
+// applyIncrementalDump reads the object named by uri (a gs://bucket/object or
+// gs://bucket/object#generation reference returned by
+// resolvePointInTimeBackups) and executes each INSERT statement it contains
+// against dbConn, replaying the incremental/binlog dump written by
+// exportCloudSQLDatabaseIncremental.
+func (c *CloudSQL) applyIncrementalDump(dbConn *sql.DB, uri string) error {
+	bucket, object, generation, err := parseGenerationURI(uri)
+	if err != nil {
+		return err
+	}
+
+	obj := c.storageSvc.Bucket(bucket).Object(object)
+	if generation != 0 {
+		obj = obj.Generation(generation)
+	}
+	reader, err := obj.NewReader(c.ctx)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range splitSQLStatements(string(data)) {
+		stmt = strings.TrimSpace(stmt)
+		if stmt == "" {
+			continue
+		}
+		if _, err := dbConn.Exec(stmt + ";"); err != nil {
+			return fmt.Errorf("apply incremental dump %s: %w", uri, err)
+		}
+	}
+	return nil
+}
+
+// splitSQLStatements splits a dump of semicolon-terminated statements (as
+// written by exportCloudSQLDatabaseIncremental, one INSERT per statement)
+// into individual statements, without the terminating ";". A naive
+// strings.Split on "\n" breaks as soon as a text/[]byte column's value
+// contains an embedded newline, since sqlLiteral renders it inline rather
+// than escaping it; this instead tracks single-quoted string state so a
+// semicolon (or newline) inside a literal - including a doubled quote mark
+// pair sqlLiteral escapes an embedded quote as, which toggles the state
+// twice and cancels out - is never mistaken for a statement boundary.
+func splitSQLStatements(data string) []string {
+	var stmts []string
+	var inString bool
+	start := 0
+	for i := 0; i < len(data); i++ {
+		switch data[i] {
+		case '\'':
+			inString = !inString
+		case ';':
+			if !inString {
+				stmts = append(stmts, data[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if rest := strings.TrimSpace(data[start:]); rest != "" {
+		stmts = append(stmts, rest)
+	}
+	return stmts
+}
+
+// parseGenerationURI splits a gs://bucket/object or
+// gs://bucket/object#generation reference into its parts. generation is 0
+// when the reference carries none, meaning "the object's live generation".
+func parseGenerationURI(uri string) (bucket, object string, generation int64, err error) {
+	trimmed := strings.TrimPrefix(uri, "gs://")
+	objectPart := trimmed
+	if hashIdx := strings.LastIndex(trimmed, "#"); hashIdx != -1 {
+		objectPart = trimmed[:hashIdx]
+		generation, err = strconv.ParseInt(trimmed[hashIdx+1:], 10, 64)
+		if err != nil {
+			return "", "", 0, fmt.Errorf("invalid generation in %q: %w", uri, err)
+		}
+	}
+
+	slashIdx := strings.Index(objectPart, "/")
+	if slashIdx == -1 {
+		return "", "", 0, fmt.Errorf("invalid gs:// URI %q", uri)
+	}
+	return objectPart[:slashIdx], objectPart[slashIdx+1:], generation, nil
+}
+
 func (c *CloudSQL) Restore(opts *RestoreOptions) (*string, error) {
+	backLocation := bakstorage.NewLocation(opts.File)
+
+	var targetTime time.Time
+	var incrementals []string
+	switch {
+	case opts.PointInTime != "":
+		pointInTime, err := time.Parse(time.RFC3339, opts.PointInTime)
+		if err != nil {
+			slog.Error("invalid RestoreOptions.PointInTime", "point_in_time", opts.PointInTime, "error", err)
+			return nil, fmt.Errorf("invalid point-in-time %q: %w", opts.PointInTime, err)
+		}
+
+		fullURI, incrs, err := c.resolvePointInTimeBackups(opts.Bucket, backLocation.Instance, backLocation.Database, pointInTime)
+		if err != nil {
+			slog.Error("Failed to resolve point-in-time backup chain", "point_in_time", opts.PointInTime, "error", err)
+			return nil, err
+		}
+		if fullURI != opts.File {
+			slog.Info("Using GCS-generation full backup for point-in-time restore", "requested", opts.File, "using", fullURI)
+			opts.File = fullURI
+			backLocation = bakstorage.NewLocation(opts.File)
+		}
+		incrementals = incrs
+	case opts.Timestamp != "":
+		var err error
+		targetTime, err = time.Parse(time.RFC3339, opts.Timestamp)
+		if err != nil {
+			slog.Error("invalid RestoreOptions.Timestamp", "timestamp", opts.Timestamp, "error", err)
+			return nil, fmt.Errorf("invalid timestamp %q: %w", opts.Timestamp, err)
+		}
+
+		closestFile, err := c.findClosestBackupBefore(opts.Bucket, backLocation.Instance, backLocation.Database, targetTime)
+		if err != nil {
+			slog.Error("Failed to find a backup to restore before timestamp", "timestamp", opts.Timestamp, "error", err)
+			return nil, err
+		}
+		if closestFile != opts.File {
+			slog.Info("Using closest preceding backup for point-in-time restore", "requested", opts.File, "using", closestFile)
+			opts.File = closestFile
+			backLocation = bakstorage.NewLocation(opts.File)
+		}
+	}
+
+	// The restore instance's engine is taken from the backup manifest written
+	// alongside the dump, if any, and falls back to Postgres for backups
+	// taken before manifests recorded EngineVersion.
+	databaseVersion := "POSTGRES_13"
+	manifest, ok, err := c.loadManifest(backLocation)
+	if err != nil {
+		slog.Error("Failed to read backup manifest", "file", opts.File, "error", err)
+		return nil, err
+	}
+	if ok && manifest.EngineVersion != "" {
+		databaseVersion = manifest.EngineVersion
+	}
+	engine := engineFor(databaseVersion)
+
 	// Define the database instance parameters
 	password := generatePassword(12)
 	dbinstance := &sqladmin.DatabaseInstance{
 		Name:         fmt.Sprintf("restore-%s", opts.Instance),
 		InstanceType: "CLOUD_SQL_INSTANCE",
-		//TODO make this configurable
-		Region: "europe-west3",
+		Region:       c.Region,
 		Settings: &sqladmin.Settings{
 			Tier:             "db-f1-micro", //TODO make it configurable Change as needed
 			ActivationPolicy: "ALWAYS",
@@ -485,39 +1479,50 @@ func (c *CloudSQL) Restore(opts *RestoreOptions) (*string, error) {
 			},
 		},
 		RootPassword:    password,
-		DatabaseVersion: "POSTGRES_13", //TODO get version from backup file
+		DatabaseVersion: databaseVersion,
 	}
 
 	slog.Info("Check if restore instance exists", "instance", dbinstance.Name)
 	db, err := c.sqlAdminSvc.Instances.Get(c.ProjectID, dbinstance.Name).Do()
 	if err != nil && err.(*googleapi.Error).Code != 404 {
-		slog.Error("Failed to get PostgreSQL instance", "instance", dbinstance.Name, "error", err)
+		slog.Error("Failed to get Cloud SQL instance", "instance", dbinstance.Name, "error", err)
 		return nil, err
 	}
 
 	if db == nil {
 		// Store the password for the new created database instance if requested
 		if opts.StoreSecret {
-			err := c.savePassword(password, dbinstance.Name)
+			secretCtx, secretSpan := tracing.Start(c.ctx, "restore.save_password_secret",
+				attribute.String("instance", dbinstance.Name), attribute.String("project", c.ProjectID))
+			err := c.savePassword(password, dbinstance.Name, opts.SecretReplication)
+			secretSpan.End()
 			if err != nil {
+				slog.ErrorContext(secretCtx, "Failed to save restore instance password", "instance", dbinstance.Name, "error", err)
 				return nil, err
 			}
 		}
 
-		// Create the PostgreSQL instance
-		slog.Info("Create PostgreSQL instance", "instance", dbinstance.Name)
+		instanceCtx, instanceSpan := tracing.Start(c.ctx, "restore.create_instance",
+			attribute.String("instance", dbinstance.Name), attribute.String("project", c.ProjectID))
+
+		// Create the Cloud SQL instance
+		slog.InfoContext(instanceCtx, "Create Cloud SQL instance", "instance", dbinstance.Name, "engine", databaseVersion)
 		operation, err := c.sqlAdminSvc.Instances.Insert(c.ProjectID, dbinstance).Context(c.ctx).Do()
 		if err != nil {
-			slog.Error("Failed to create PostgreSQL instance", "instance", dbinstance.Name, "error", err)
+			slog.ErrorContext(instanceCtx, "Failed to create Cloud SQL instance", "instance", dbinstance.Name, "error", err)
+			instanceSpan.End()
 			return nil, err
 		}
+		instanceSpan.SetAttributes(attribute.String("operation_id", operation.Name))
 
 		// Wait for the operation to complete
-		if err := c.WaitForSQLOperation(time.Minute*1, operation); err != nil {
-			slog.Error("Failed to create PostgreSQL instance", "instance", dbinstance.Name, "error", err)
+		if err := c.WaitForSQLOperation(DefaultWaitConfig, operation); err != nil {
+			slog.ErrorContext(instanceCtx, "Failed to create Cloud SQL instance", "instance", dbinstance.Name, "error", err)
+			instanceSpan.End()
 			return nil, err
 		}
-		slog.Info("Successfully created PostgreSQL instance", "instance", dbinstance.Name)
+		slog.InfoContext(instanceCtx, "Successfully created Cloud SQL instance", "instance", dbinstance.Name)
+		instanceSpan.End()
 	} else {
 		secretVersion, err := c.secretSvc.AccessSecretVersion(c.ctx, &secretmanagerpb.AccessSecretVersionRequest{
 			Name: fmt.Sprintf("projects/%s/secrets/%s/versions/latest", c.ProjectID, strings.ToUpper(dbinstance.Name)),
@@ -530,33 +1535,35 @@ func (c *CloudSQL) Restore(opts *RestoreOptions) (*string, error) {
 		password = string(secretVersion.Payload.Data)
 	}
 
-	backLocation := bakstorage.NewLocation(opts.File)
-
 	database := &sqladmin.Database{
 		Name: backLocation.Database,
 	}
 
 	dbase, err := c.sqlAdminSvc.Databases.Get(c.ProjectID, dbinstance.Name, database.Name).Do()
 	if err != nil && err.(*googleapi.Error).Code != 404 {
-		slog.Error("Failed to get PostgreSQL instance database", "instance", dbinstance.Name, "database", database.Name, "error", err)
+		slog.Error("Failed to get Cloud SQL database", "instance", dbinstance.Name, "database", database.Name, "error", err)
 	}
 
 	if dbase == nil {
 		operation, err := c.sqlAdminSvc.Databases.Insert(c.ProjectID, dbinstance.Name, database).Context(c.ctx).Do()
 		if err != nil {
-			slog.Error("Failed to create PostgreSQL instance database", "instance", dbinstance.Name, "database", database.Name, "error", err)
+			slog.Error("Failed to create Cloud SQL database", "instance", dbinstance.Name, "database", database.Name, "error", err)
 			return nil, err
 		}
 
 		// Wait for the operation to complete
-		if err := c.WaitForSQLOperation(time.Second*10, operation); err != nil {
-			slog.Error("Failed to create PostgreSQL instance database", "instance", dbinstance.Name, "database", database.Name, "error", err)
+		if err := c.WaitForSQLOperation(ShortWaitConfig, operation); err != nil {
+			slog.Error("Failed to create Cloud SQL database", "instance", dbinstance.Name, "database", database.Name, "error", err)
 			return nil, err
 		}
-		slog.Info("Successfully created PostgreSQL instance database", "instance", dbinstance.Name, "database", database.Name)
+		slog.Info("Successfully created Cloud SQL database", "instance", dbinstance.Name, "database", database.Name)
 	}
 
-	reader, err := c.storageSvc.Bucket(backLocation.Bucket).Object(backLocation.UserLocation()).NewReader(c.ctx)
+	store, _, err := c.blobStoreFor(backLocation.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := store.Open(c.ctx, backLocation.UserLocation())
 	if err != nil {
 		slog.Error("Failed to open file", "location", backLocation.UserLocation(), "error", err)
 		return nil, err
@@ -586,15 +1593,15 @@ func (c *CloudSQL) Restore(opts *RestoreOptions) (*string, error) {
 		if u == nil {
 			operation, err := c.sqlAdminSvc.Users.Insert(c.ProjectID, dbinstance.Name, sqlUser).Context(c.ctx).Do()
 			if err != nil {
-				slog.Error("Failed to create PostgreSQL user", "instance", dbinstance.Name, "database", database.Name, "user", sqlUser.Name, "error", err)
+				slog.Error("Failed to create Cloud SQL user", "instance", dbinstance.Name, "database", database.Name, "user", sqlUser.Name, "error", err)
 				return nil, err
 			}
 			// Wait for the operation to complete
-			if err := c.WaitForSQLOperation(time.Second*10, operation); err != nil {
-				slog.Error("Failed to create PostgreSQL user", "instance", dbinstance.Name, "database", database.Name, "user", sqlUser.Name, "error", err)
+			if err := c.WaitForSQLOperation(ShortWaitConfig, operation); err != nil {
+				slog.Error("Failed to create Cloud SQL user", "instance", dbinstance.Name, "database", database.Name, "user", sqlUser.Name, "error", err)
 				return nil, err
 			}
-			slog.Info("Successfully created PostgreSQL user", "instance", dbinstance.Name, "database", database.Name, "user", sqlUser.Name)
+			slog.Info("Successfully created Cloud SQL user", "instance", dbinstance.Name, "database", database.Name, "user", sqlUser.Name)
 		}
 	}
 
@@ -626,59 +1633,136 @@ func (c *CloudSQL) Restore(opts *RestoreOptions) (*string, error) {
 		return nil, err
 	}
 
-	slog.Info("Import data", "instance", dbinstance.Name, "file", opts.File)
-	// Import data from SQL file
+	if err := c.verifyBackupChecksum(backLocation, opts.File); err != nil {
+		slog.Error("Backup integrity verification failed", "file", opts.File, "error", err)
+		return nil, err
+	}
+
+	importCtx, importSpan := tracing.Start(c.ctx, "restore.import",
+		attribute.String("instance", dbinstance.Name), attribute.String("project", c.ProjectID))
+	defer importSpan.End()
+
+	importFile := opts.File
+	if opts.KMSKey != "" {
+		decryptedFile, cleanup, err := c.decryptImportObject(opts.KMSKey, opts.File)
+		if err != nil {
+			slog.ErrorContext(importCtx, "Failed to decrypt backup for import", "file", opts.File, "error", err)
+			return nil, err
+		}
+		defer cleanup()
+		importFile = decryptedFile
+	}
+
+	slog.InfoContext(importCtx, "Import data", "instance", dbinstance.Name, "file", importFile)
+	// Import data from the backup file, using the engine-specific request
+	// shape (e.g. SQL Server imports a .bak via BakImportOptions rather than
+	// a plain SQL dump).
 	importReq := &sqladmin.InstancesImportRequest{
-		ImportContext: &sqladmin.ImportContext{
-			Kind:       "sql#importContext",
-			Database:   database.Name,
-			FileType:   "SQL",
-			ImportUser: opts.User,
-			Uri:        opts.File, // You can also use local file path here
-			//TODO check what bak import and export is capable of
-			// BakImportOptions: &sqladmin.ImportContextBakImportOptions{
-
-			// },
-		},
+		ImportContext: engine.ImportRequest(database.Name, importFile, opts.User),
 	}
 
 	importOp, err := c.sqlAdminSvc.Instances.Import(c.ProjectID, fmt.Sprintf("restore-%s", opts.Instance), importReq).Context(c.ctx).Do()
 	if err != nil {
-		slog.Error("Failed to import data", "file", opts.File, "error", err)
+		slog.ErrorContext(importCtx, "Failed to import data", "file", importFile, "error", err)
 		return nil, err
 	}
+	importSpan.SetAttributes(attribute.String("operation_id", importOp.Name))
 
 	// Wait for the import operation to complete
-	if err := c.WaitForSQLOperation(time.Minute*1, importOp); err != nil {
-		slog.Error("Failed to import data", "error", err)
+	if err := c.WaitForSQLOperation(DefaultWaitConfig, importOp); err != nil {
+		slog.ErrorContext(importCtx, "Failed to import data", "error", err)
 		return nil, err
 	}
 
-	slog.Info("Data imported successfully", "instance", dbinstance.Name, "file", opts.File)
+	slog.InfoContext(importCtx, "Data imported successfully", "instance", dbinstance.Name, "file", importFile)
 
-	//TODO make the system user be configurable
-	stats, err := c.GetCloudSQLStatistic(dbinstance.Name, "postgres", password, database.Name)
+	if len(incrementals) > 0 {
+		slog.Info("Replaying incremental backups for point-in-time restore", "instance", dbinstance.Name, "point_in_time", opts.PointInTime, "count", len(incrementals))
+		conn := Connection{
+			Engine:   engineFromDatabaseVersion(databaseVersion),
+			User:     engine.DefaultUser(),
+			Password: password,
+			Database: database.Name,
+			URL:      fmt.Sprintf("%s:%s:%s", c.ProjectID, dbinstance.Region, dbinstance.Name),
+		}
+		dbConn, err := conn.Connect()
+		if err != nil {
+			slog.Error("Failed to connect to restore instance for incremental replay", "instance", dbinstance.Name, "error", err)
+			return nil, err
+		}
+		defer dbConn.Close()
 
-	statsBackup := make(map[string]*CloudSQLStatistic)
+		for _, uri := range incrementals {
+			if err := c.applyIncrementalDump(dbConn, uri); err != nil {
+				slog.Error("Failed to replay incremental backup", "instance", dbinstance.Name, "backup", uri, "error", err)
+				return nil, err
+			}
+		}
+		slog.Info("Incremental backups replayed successfully", "instance", dbinstance.Name, "point_in_time", opts.PointInTime)
+	}
+
+	if opts.Timestamp != "" {
+		// Cloud SQL's native PITR clone replays the source instance's own
+		// transaction log, not the dump just imported into dbinstance, so it
+		// must clone opts.Instance (which has that log) into a fresh
+		// destination name - Clone rejects a destination that already
+		// exists, and dbinstance was already created above.
+		pitrInstanceName := fmt.Sprintf("restore-pitr-%s", opts.Instance)
+
+		cloneCtx, cloneSpan := tracing.Start(c.ctx, "restore.clone_pointintime",
+			attribute.String("instance", opts.Instance), attribute.String("project", c.ProjectID))
+
+		slog.InfoContext(cloneCtx, "Starting point-in-time recovery", "instance", opts.Instance, "destination", pitrInstanceName, "timestamp", opts.Timestamp)
+		cloneOp, err := c.sqlAdminSvc.Instances.Clone(c.ProjectID, opts.Instance, &sqladmin.InstancesCloneRequest{
+			CloneContext: &sqladmin.CloneContext{
+				DestinationInstanceName: pitrInstanceName,
+				PointInTime:             targetTime.Format(time.RFC3339),
+			},
+		}).Context(c.ctx).Do()
+		if err != nil {
+			slog.ErrorContext(cloneCtx, "Failed to start point-in-time recovery", "instance", opts.Instance, "timestamp", opts.Timestamp, "error", err)
+			cloneSpan.End()
+			return nil, err
+		}
+		cloneSpan.SetAttributes(attribute.String("operation_id", cloneOp.Name))
+
+		if err := c.WaitForSQLOperation(DefaultWaitConfig, cloneOp); err != nil {
+			slog.ErrorContext(cloneCtx, "Failed point-in-time recovery", "instance", opts.Instance, "timestamp", opts.Timestamp, "error", err)
+			cloneSpan.End()
+			return nil, err
+		}
+		slog.InfoContext(cloneCtx, "Point-in-time recovery complete", "instance", pitrInstanceName, "timestamp", opts.Timestamp)
+		cloneSpan.End()
+
+		// The clone supersedes the import-based restore instance created
+		// above; delete it and use the clone as the restored instance for
+		// stats, verification and cleanup below.
+		slog.Info("Deleting import-based restore instance superseded by point-in-time clone", "instance", dbinstance.Name)
+		delOp, err := c.sqlAdminSvc.Instances.Delete(c.ProjectID, dbinstance.Name).Context(c.ctx).Do()
+		if err != nil {
+			slog.Error("Failed to delete superseded restore instance", "instance", dbinstance.Name, "error", err)
+			return nil, err
+		}
+		if err := c.WaitForSQLOperation(DefaultWaitConfig, delOp); err != nil {
+			slog.Error("Failed to delete superseded restore instance", "instance", dbinstance.Name, "error", err)
+			return nil, err
+		}
+		dbinstance.Name = pitrInstanceName
+	}
 
-	object := c.storageSvc.
-		Bucket(backLocation.Bucket).
-		Object(backLocation.StatsLocation())
+	stats, err := c.GetCloudSQLStatistic(dbinstance.Name, engine.DefaultUser(), password, database.Name, opts.DeepVerify, opts.StatsParallelism)
 
-	_, err = object.Attrs(c.ctx)
-	if err != nil && err != storage.ErrObjectNotExist {
-		slog.Error("Failed to retrieve bucket object", "location", backLocation.StatsLocation(), "error", err)
+	statsBackup := make(map[string]*CloudSQLStatistic)
+
+	reader, err = store.Open(c.ctx, backLocation.StatsLocation(database.Name))
+	if err != nil && !errors.Is(err, ErrBlobNotExist) {
+		slog.Error("Failed to retrieve bucket object", "location", backLocation.StatsLocation(database.Name), "error", err)
 		return nil, err
 	}
 
 	//Only check restore integrity when stats yaml file exists. If not, skip the check
 	//The stats will be created during the backup process if ExportStats is enabled
-	if err != storage.ErrObjectNotExist {
-		reader, err = object.NewReader(c.ctx)
-		if err != nil {
-			slog.Error("Failed to read user file", "location", backLocation.StatsLocation(), "error", err)
-			return nil, err
-		}
+	if !errors.Is(err, ErrBlobNotExist) {
 		defer reader.Close()
 
 		err = yaml.NewDecoder(reader).Decode(&statsBackup)
@@ -697,12 +1781,50 @@ func (c *CloudSQL) Restore(opts *RestoreOptions) (*string, error) {
 				slog.Error("Row count mismatch", "key", key, "value", value.RowCount, "backup", statsBackup[key].RowCount)
 				validationErrors = append(validationErrors, fmt.Errorf("row count mismatch key: %s, value: %d, backup: %d", key, value.RowCount, statsBackup[key].RowCount))
 			}
+			if opts.DeepVerify && value.ContentChecksum != statsBackup[key].ContentChecksum {
+				slog.Error("Content checksum mismatch", "key", key, "value", value.ContentChecksum, "backup", statsBackup[key].ContentChecksum)
+				validationErrors = append(validationErrors, fmt.Errorf("content checksum mismatch key: %s, value: %s, backup: %s", key, value.ContentChecksum, statsBackup[key].ContentChecksum))
+			}
 		}
 		if validationErrors != nil {
 			return nil, errors.Join(validationErrors...)
 		}
 	} else {
-		slog.Info("Stats file not found, skipping validation", "location", backLocation.StatsLocation())
+		slog.Info("Stats file not found, skipping validation", "location", backLocation.StatsLocation(database.Name))
+	}
+
+	if opts.Password != "" {
+		verifyCtx, verifySpan := tracing.Start(c.ctx, "restore.verify",
+			attribute.String("instance", dbinstance.Name), attribute.String("project", c.ProjectID))
+
+		report, err := c.Verify(opts.Instance, opts.Password, dbinstance.Name, password, database.Name, opts.StatsParallelism)
+		if err != nil {
+			slog.ErrorContext(verifyCtx, "Failed to verify restore", "instance", dbinstance.Name, "error", err)
+			verifySpan.End()
+			return nil, err
+		}
+		if !report.OK() {
+			verifySpan.End()
+			return nil, fmt.Errorf("%w for database %q: %d of %d tables mismatched", ErrVerificationFailed, database.Name, report.MismatchCount(), len(report.Tables))
+		}
+		slog.InfoContext(verifyCtx, "Restore verification passed", "instance", dbinstance.Name, "database", database.Name)
+		verifySpan.End()
+
+		if opts.Cleanup {
+			slog.Info("Deleting restore instance after successful verification", "instance", dbinstance.Name)
+			op, err := c.sqlAdminSvc.Instances.Delete(c.ProjectID, dbinstance.Name).Context(c.ctx).Do()
+			if err != nil {
+				slog.Error("Failed to delete restore instance", "instance", dbinstance.Name, "error", err)
+				return nil, err
+			}
+			if err := c.WaitForSQLOperation(DefaultWaitConfig, op); err != nil {
+				slog.Error("Failed to delete restore instance", "instance", dbinstance.Name, "error", err)
+				return nil, err
+			}
+			slog.Info("Restore instance deleted", "instance", dbinstance.Name)
+		}
+	} else if opts.Cleanup {
+		slog.Info("Skipping cleanup: Password is unset so Verify did not run", "instance", dbinstance.Name)
 	}
 
 	return &dbinstance.Name, nil
@@ -0,0 +1,104 @@
+package cloudsql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngineFromDatabaseVersion(t *testing.T) {
+	test := []struct {
+		version  string
+		expected Engine
+	}{
+		{"POSTGRES_13", EnginePostgres},
+		{"POSTGRES_15", EnginePostgres},
+		{"MYSQL_8_0", EngineMySQL},
+		{"SQLSERVER_2019_STANDARD", EngineSQLServer},
+	}
+
+	for _, tt := range test {
+		assert.Equal(t, tt.expected, engineFromDatabaseVersion(tt.version))
+	}
+}
+
+func TestTableContentChecksumQuerySQLServerUnsupported(t *testing.T) {
+	assert.Empty(t, tableContentChecksumQuery(EngineSQLServer, "dbo.accounts"))
+	assert.NotEmpty(t, tableContentChecksumQuery(EnginePostgres, "public.accounts"))
+	assert.NotEmpty(t, tableContentChecksumQuery(EngineMySQL, "mydb.accounts"))
+}
+
+func TestSplitBucketScheme(t *testing.T) {
+	scheme, name := splitBucketScheme("my-bucket")
+	assert.Empty(t, scheme)
+	assert.Equal(t, "my-bucket", name)
+
+	scheme, name = splitBucketScheme("gs://my-bucket")
+	assert.Equal(t, "gs", scheme)
+	assert.Equal(t, "my-bucket", name)
+
+	scheme, name = splitBucketScheme("s3://my-bucket")
+	assert.Equal(t, "s3", scheme)
+	assert.Equal(t, "my-bucket", name)
+}
+
+func TestNewBlobStoreUnsupportedScheme(t *testing.T) {
+	_, _, err := NewBlobStore(context.Background(), nil, "az://my-bucket")
+	assert.ErrorContains(t, err, "unsupported storage backend scheme")
+}
+
+func TestVerifyReportOK(t *testing.T) {
+	report := VerifyReport{Tables: []TableVerification{
+		{FullTableName: "public.accounts"},
+		{FullTableName: "public.orders"},
+	}}
+	assert.True(t, report.OK())
+	assert.Equal(t, 0, report.MismatchCount())
+
+	report.Tables[1].RowCountMismatch = true
+	assert.False(t, report.OK())
+	assert.Equal(t, 1, report.MismatchCount())
+}
+
+func TestTableSchemaQueryPerEngine(t *testing.T) {
+	assert.Contains(t, tableSchemaQuery(EngineMySQL), "GROUP_CONCAT")
+	assert.Contains(t, tableSchemaQuery(EngineSQLServer), "STRING_AGG")
+	assert.Contains(t, tableSchemaQuery(EnginePostgres), "string_agg")
+}
+
+func TestParseGenerationURI(t *testing.T) {
+	bucket, object, generation, err := parseGenerationURI("gs://my-bucket/instance/cloudsql/binlogs/db-20240101T000000.sql#12345")
+	assert.NoError(t, err)
+	assert.Equal(t, "my-bucket", bucket)
+	assert.Equal(t, "instance/cloudsql/binlogs/db-20240101T000000.sql", object)
+	assert.EqualValues(t, 12345, generation)
+
+	bucket, object, generation, err = parseGenerationURI("gs://my-bucket/instance/cloudsql/db-20240101T000000.sql")
+	assert.NoError(t, err)
+	assert.Equal(t, "my-bucket", bucket)
+	assert.Equal(t, "instance/cloudsql/db-20240101T000000.sql", object)
+	assert.EqualValues(t, 0, generation)
+
+	_, _, _, err = parseGenerationURI("gs://my-bucket/instance/cloudsql/db-20240101T000000.sql#not-a-number")
+	assert.Error(t, err)
+}
+
+func TestSplitSQLStatementsEmbeddedNewline(t *testing.T) {
+	data := "INSERT INTO notes VALUES (1, 'line one\nline two');\nINSERT INTO notes VALUES (2, 'ok');\n"
+
+	stmts := splitSQLStatements(data)
+
+	assert.Equal(t, []string{
+		"INSERT INTO notes VALUES (1, 'line one\nline two')",
+		"\nINSERT INTO notes VALUES (2, 'ok')",
+	}, stmts)
+}
+
+func TestSplitSQLStatementsEscapedQuote(t *testing.T) {
+	data := "INSERT INTO notes VALUES (1, 'it''s; still one value');\n"
+
+	stmts := splitSQLStatements(data)
+
+	assert.Equal(t, []string{"INSERT INTO notes VALUES (1, 'it''s; still one value')"}, stmts)
+}
@@ -7,12 +7,24 @@ import (
 	"net"
 
 	"cloud.google.com/go/cloudsqlconn"
+	mssqlconn "cloud.google.com/go/cloudsqlconn/mssql/mssql"
+	mysqlconn "cloud.google.com/go/cloudsqlconn/mysql/mysql"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/stdlib"
 )
 
+// Engine identifies the database engine a Connection talks to.
+type Engine string
+
+const (
+	EnginePostgres  Engine = "postgres"
+	EngineMySQL     Engine = "mysql"
+	EngineSQLServer Engine = "sqlserver"
+)
+
 type Connection struct {
+	Engine   Engine // defaults to EnginePostgres when empty, for backwards compatibility
 	User     string
 	Password string
 	Database string
@@ -33,18 +45,28 @@ func (c Connection) Connect(connOpts ...Option) (*sql.DB, error) {
 	for _, opt := range connOpts {
 		opt(&c)
 	}
-	dsn := fmt.Sprintf("user=%s password=%s database=%s", c.User, c.Password, c.Database)
-	config, err := pgx.ParseConfig(dsn)
-	if err != nil {
-		return nil, err
+
+	switch c.Engine {
+	case EngineMySQL:
+		return c.connectMySQL()
+	case EngineSQLServer:
+		return c.connectSQLServer()
+	default:
+		return c.connectPostgres()
 	}
-	var opts []cloudsqlconn.Option
-	d, err := cloudsqlconn.NewDialer(context.Background(), opts...)
+}
+
+func (c Connection) connectPostgres() (*sql.DB, error) {
+	config, err := postgresConfig(c)
 	if err != nil {
 		return nil, err
 	}
 
 	if c.dialFunc == nil {
+		d, err := cloudsqlconn.NewDialer(context.Background())
+		if err != nil {
+			return nil, err
+		}
 		c.dialFunc = func(ctx context.Context, network, instance string) (net.Conn, error) {
 			return d.Dial(ctx, c.URL)
 		}
@@ -59,3 +81,46 @@ func (c Connection) Connect(connOpts ...Option) (*sql.DB, error) {
 	}
 	return dbPool, nil
 }
+
+// postgresConfig builds the pgx connection config for c, setting
+// User/Password/Database directly on the parsed config rather than
+// interpolating them into a libpq keyword/value DSN string. Auto-generated
+// Cloud SQL passwords routinely contain characters (spaces, backslashes,
+// quotes, '=') that a hand-built DSN would mis-parse or that would make a
+// naive string-concat DSN injectable.
+func postgresConfig(c Connection) (*pgx.ConnConfig, error) {
+	config, err := pgx.ParseConfig("")
+	if err != nil {
+		return nil, err
+	}
+	config.User = c.User
+	config.Password = c.Password
+	config.Database = c.Database
+	return config, nil
+}
+
+func (c Connection) connectMySQL() (*sql.DB, error) {
+	if _, err := mysqlconn.RegisterDriver("cloudsql-mysql"); err != nil {
+		return nil, fmt.Errorf("mysql.RegisterDriver: %w", err)
+	}
+
+	dsn := fmt.Sprintf("%s:%s@cloudsql-mysql(%s)/%s", c.User, c.Password, c.URL, c.Database)
+	dbPool, err := sql.Open("cloudsql-mysql", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("sql.Open: %w", err)
+	}
+	return dbPool, nil
+}
+
+func (c Connection) connectSQLServer() (*sql.DB, error) {
+	if _, err := mssqlconn.RegisterDriver("cloudsql-sqlserver"); err != nil {
+		return nil, fmt.Errorf("mssql.RegisterDriver: %w", err)
+	}
+
+	dsn := fmt.Sprintf("sqlserver://%s:%s@%s?database=%s", c.User, c.Password, c.URL, c.Database)
+	dbPool, err := sql.Open("cloudsql-sqlserver", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("sql.Open: %w", err)
+	}
+	return dbPool, nil
+}
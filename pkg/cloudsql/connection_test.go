@@ -19,6 +19,34 @@ var localPostgresDialer = func(cfg *pgx.ConnConfig) pgconn.DialFunc {
 	}
 }
 
+func TestPostgresConfigEscapesSpecialCharacters(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+	}{
+		{"simple", "letmein123"},
+		{"space", "pass word"},
+		{"single quote", "pass'word"},
+		{"double quote", `pass"word`},
+		{"backslash", `pass\word`},
+		{"equals", "pass=word"},
+		{"combined", ` pass ' " \ = word `},
+		{"non-ascii", "pässwörd-日本語"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := Connection{User: "exporter", Password: tt.password, Database: "mydb"}
+
+			config, err := postgresConfig(conn)
+			assert.NoError(t, err)
+			assert.Equal(t, conn.User, config.User)
+			assert.Equal(t, conn.Password, config.Password)
+			assert.Equal(t, conn.Database, config.Database)
+		})
+	}
+}
+
 func TestNewConnection(t *testing.T) {
 	ctx := context.Background()
 
@@ -0,0 +1,216 @@
+package cloudsql
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	bakstorage "github.com/fr12k/cloudsql-exporter/pkg/storage"
+
+	kms "cloud.google.com/go/kms/apiv1"
+	"cloud.google.com/go/kms/apiv1/kmspb"
+)
+
+// dekMetadataKey is the GCS object metadata key EncryptExportedObject stores
+// an object's wrapped data encryption key under, so decryptImportObject can
+// find and unwrap it again without a sibling object to keep in sync.
+const dekMetadataKey = "cloudsql-exporter-wrapped-dek"
+
+// generateDEK creates a fresh random AES-256 key to encrypt a single
+// exported object. Each object gets its own DEK so compromising one
+// wrapped key never exposes more than the object it was generated for.
+func generateDEK() ([]byte, error) {
+	dek := make([]byte, 32)
+	if _, err := rand.Read(dek); err != nil {
+		return nil, fmt.Errorf("generate data encryption key: %w", err)
+	}
+	return dek, nil
+}
+
+// encryptDump seals plaintext with dek using AES-256-GCM, prefixing the
+// returned ciphertext with the nonce so decryptDump can recover it without a
+// side channel.
+func encryptDump(dek, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(dek)
+	if err != nil {
+		return nil, fmt.Errorf("init AES cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("init AES-GCM: %w", err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// decryptDump reverses encryptDump, reading the nonce back off the front of
+// ciphertext.
+func decryptDump(dek, ciphertext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(dek)
+	if err != nil {
+		return nil, fmt.Errorf("init AES cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("init AES-GCM: %w", err)
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext shorter than nonce size")
+	}
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, sealed, nil)
+}
+
+// wrapDEK encrypts dek with the Cloud KMS key named by kmsKeyName (a full
+// resource name, e.g.
+// "projects/p/locations/l/keyRings/r/cryptoKeys/k"), so the GCS object
+// storing dek's wrapped form carries no secret an attacker could use
+// without also holding Decrypt permission on kmsKeyName.
+func wrapDEK(ctx context.Context, kmsSvc *kms.KeyManagementClient, kmsKeyName string, dek []byte) ([]byte, error) {
+	resp, err := kmsSvc.Encrypt(ctx, &kmspb.EncryptRequest{
+		Name:      kmsKeyName,
+		Plaintext: dek,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kms encrypt: %w", err)
+	}
+	return resp.Ciphertext, nil
+}
+
+// unwrapDEK reverses wrapDEK via the same Cloud KMS key.
+func unwrapDEK(ctx context.Context, kmsSvc *kms.KeyManagementClient, kmsKeyName string, wrappedDEK []byte) ([]byte, error) {
+	resp, err := kmsSvc.Decrypt(ctx, &kmspb.DecryptRequest{
+		Name:       kmsKeyName,
+		Ciphertext: wrappedDEK,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kms decrypt: %w", err)
+	}
+	return resp.Plaintext, nil
+}
+
+// EncryptExportedObject downloads the object at bucket/key, encrypts it with
+// a fresh per-object DEK, and re-uploads the ciphertext in place, storing
+// the DEK -- itself wrapped by the Cloud KMS key kmsKeyName -- in the
+// object's metadata for decryptImportObject to recover later. Cloud SQL's
+// Instances.Export API writes a dump straight to GCS, so the exporter never
+// sees the plaintext bytes in flight; this always runs as a
+// download-encrypt-reupload step once the export completes, mirroring how
+// pkg/backup's checksumObjects re-reads freshly-exported objects for
+// BackupOptions.Checksum.
+func (c *CloudSQL) EncryptExportedObject(kmsKeyName, bucket, key string) error {
+	obj := c.storageSvc.Bucket(bucket).Object(key)
+
+	reader, err := obj.NewReader(c.ctx)
+	if err != nil {
+		return fmt.Errorf("open %s/%s for encryption: %w", bucket, key, err)
+	}
+	plaintext, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return fmt.Errorf("read %s/%s for encryption: %w", bucket, key, err)
+	}
+
+	dek, err := generateDEK()
+	if err != nil {
+		return err
+	}
+	ciphertext, err := encryptDump(dek, plaintext)
+	if err != nil {
+		return fmt.Errorf("encrypt %s/%s: %w", bucket, key, err)
+	}
+	wrappedDEK, err := wrapDEK(c.ctx, c.kmsSvc, kmsKeyName, dek)
+	if err != nil {
+		return fmt.Errorf("wrap data encryption key for %s/%s: %w", bucket, key, err)
+	}
+
+	writer := obj.NewWriter(c.ctx)
+	writer.Metadata = map[string]string{dekMetadataKey: base64.StdEncoding.EncodeToString(wrappedDEK)}
+	if _, err := writer.Write(ciphertext); err != nil {
+		writer.Close()
+		return fmt.Errorf("write encrypted %s/%s: %w", bucket, key, err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("finalize encrypted %s/%s: %w", bucket, key, err)
+	}
+
+	slog.Info("Encrypted exported object", "bucket", bucket, "key", key, "kms_key", kmsKeyName)
+	return nil
+}
+
+// decryptImportObject checks whether the object named by the gs:// URI uri
+// carries a wrapped DEK under dekMetadataKey (written by
+// EncryptExportedObject). If it does, it unwraps the DEK via the Cloud KMS
+// key kmsKeyName, decrypts the object into a temporary sibling object, and
+// returns that object's URI along with a cleanup func that removes it. If
+// the object carries no wrapped DEK, it returns uri unchanged and a no-op
+// cleanup func, so Restore can call this unconditionally once KMSKey is set.
+func (c *CloudSQL) decryptImportObject(kmsKeyName, uri string) (string, func(), error) {
+	noop := func() {}
+
+	loc := bakstorage.NewLocation(uri)
+	key := strings.TrimPrefix(uri, fmt.Sprintf("gs://%s/", loc.Bucket))
+	obj := c.storageSvc.Bucket(loc.Bucket).Object(key)
+
+	attrs, err := obj.Attrs(c.ctx)
+	if err != nil {
+		return "", noop, fmt.Errorf("read attrs for %s: %w", uri, err)
+	}
+	wrappedB64, ok := attrs.Metadata[dekMetadataKey]
+	if !ok {
+		return uri, noop, nil
+	}
+
+	wrappedDEK, err := base64.StdEncoding.DecodeString(wrappedB64)
+	if err != nil {
+		return "", noop, fmt.Errorf("decode wrapped data encryption key for %s: %w", uri, err)
+	}
+	dek, err := unwrapDEK(c.ctx, c.kmsSvc, kmsKeyName, wrappedDEK)
+	if err != nil {
+		return "", noop, fmt.Errorf("unwrap data encryption key for %s: %w", uri, err)
+	}
+
+	reader, err := obj.NewReader(c.ctx)
+	if err != nil {
+		return "", noop, fmt.Errorf("open %s for decryption: %w", uri, err)
+	}
+	ciphertext, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return "", noop, fmt.Errorf("read %s for decryption: %w", uri, err)
+	}
+
+	plaintext, err := decryptDump(dek, ciphertext)
+	if err != nil {
+		return "", noop, fmt.Errorf("decrypt %s: %w", uri, err)
+	}
+
+	tempKey := key + ".decrypted"
+	tempObj := c.storageSvc.Bucket(loc.Bucket).Object(tempKey)
+	writer := tempObj.NewWriter(c.ctx)
+	if _, err := writer.Write(plaintext); err != nil {
+		writer.Close()
+		return "", noop, fmt.Errorf("write decrypted %s: %w", tempKey, err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", noop, fmt.Errorf("finalize decrypted %s: %w", tempKey, err)
+	}
+
+	tempURI := fmt.Sprintf("gs://%s/%s", loc.Bucket, tempKey)
+	cleanup := func() {
+		if err := tempObj.Delete(c.ctx); err != nil {
+			slog.Error("Failed to delete temporary decrypted dump object", "uri", tempURI, "error", err)
+		}
+	}
+	slog.Info("Decrypted dump for import", "source", uri, "temp", tempURI)
+	return tempURI, cleanup, nil
+}
@@ -0,0 +1,34 @@
+package cloudsql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptDecryptDumpRoundTrip(t *testing.T) {
+	dek, err := generateDEK()
+	assert.NoError(t, err)
+	assert.Len(t, dek, 32)
+
+	plaintext := []byte("-- pg_dump output\nINSERT INTO accounts VALUES (1, 'alice');\n")
+	ciphertext, err := encryptDump(dek, plaintext)
+	assert.NoError(t, err)
+	assert.NotEqual(t, plaintext, ciphertext)
+
+	decrypted, err := decryptDump(dek, ciphertext)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
+
+func TestDecryptDumpRejectsTamperedCiphertext(t *testing.T) {
+	dek, err := generateDEK()
+	assert.NoError(t, err)
+
+	ciphertext, err := encryptDump(dek, []byte("some dump contents"))
+	assert.NoError(t, err)
+	ciphertext[len(ciphertext)-1] ^= 0xFF
+
+	_, err = decryptDump(dek, ciphertext)
+	assert.Error(t, err)
+}
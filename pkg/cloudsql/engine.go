@@ -0,0 +1,153 @@
+package cloudsql
+
+import (
+	"google.golang.org/api/sqladmin/v1"
+)
+
+// DatabaseEngine abstracts the engine-specific parts of exporting/importing
+// Cloud SQL backups: the sqladmin request shapes, the catalog query used to
+// collect per-table statistics, which databases this engine creates by
+// default (and so should be skipped when enumerating a fresh instance), and
+// the system user Restore connects to the restore instance as.
+type DatabaseEngine interface {
+	// ExportRequest builds the ExportContext for dumping database to uri.
+	ExportRequest(database, uri string) *sqladmin.ExportContext
+	// ImportRequest builds the ImportContext for importing uri into database
+	// as importUser.
+	ImportRequest(database, uri, importUser string) *sqladmin.ImportContext
+	// StatsQuery is the row/size statistics query GetCloudSQLStatistic runs.
+	StatsQuery() string
+	// SystemDatabases lists the databases this engine creates by default.
+	SystemDatabases() []string
+	// DefaultUser is the system user Restore connects to the restore
+	// instance as to collect statistics and replay incremental dumps.
+	DefaultUser() string
+	// ObjectSuffix is the file extension ExportRequest's Uri should carry.
+	ObjectSuffix(compression bool) string
+}
+
+// engineFor returns the DatabaseEngine for a sqladmin DatabaseVersion (e.g.
+// "POSTGRES_13", "MYSQL_8_0", "SQLSERVER_2019_STANDARD").
+func engineFor(databaseVersion string) DatabaseEngine {
+	switch engineFromDatabaseVersion(databaseVersion) {
+	case EngineMySQL:
+		return mysqlEngine{}
+	case EngineSQLServer:
+		return sqlserverEngine{}
+	default:
+		return postgresEngine{}
+	}
+}
+
+type postgresEngine struct{}
+
+func (postgresEngine) ExportRequest(database, uri string) *sqladmin.ExportContext {
+	return &sqladmin.ExportContext{
+		Kind:      "sql#exportContext",
+		FileType:  "SQL",
+		Databases: []string{database},
+		Uri:       uri,
+	}
+}
+
+func (postgresEngine) ImportRequest(database, uri, importUser string) *sqladmin.ImportContext {
+	return &sqladmin.ImportContext{
+		Kind:       "sql#importContext",
+		FileType:   "SQL",
+		Database:   database,
+		ImportUser: importUser,
+		Uri:        uri,
+	}
+}
+
+func (postgresEngine) StatsQuery() string { return statsQuery(EnginePostgres) }
+
+func (postgresEngine) SystemDatabases() []string { return []string{"postgres"} }
+
+func (postgresEngine) DefaultUser() string { return "postgres" }
+
+func (postgresEngine) ObjectSuffix(compression bool) string {
+	if compression {
+		return "sql.gz"
+	}
+	return "sql"
+}
+
+type mysqlEngine struct{}
+
+func (mysqlEngine) ExportRequest(database, uri string) *sqladmin.ExportContext {
+	return &sqladmin.ExportContext{
+		Kind:      "sql#exportContext",
+		FileType:  "SQL",
+		Databases: []string{database},
+		Uri:       uri,
+	}
+}
+
+func (mysqlEngine) ImportRequest(database, uri, importUser string) *sqladmin.ImportContext {
+	return &sqladmin.ImportContext{
+		Kind:       "sql#importContext",
+		FileType:   "SQL",
+		Database:   database,
+		ImportUser: importUser,
+		Uri:        uri,
+	}
+}
+
+func (mysqlEngine) StatsQuery() string { return statsQuery(EngineMySQL) }
+
+func (mysqlEngine) SystemDatabases() []string {
+	return []string{"mysql", "information_schema", "performance_schema", "sys"}
+}
+
+func (mysqlEngine) DefaultUser() string { return "root" }
+
+func (mysqlEngine) ObjectSuffix(compression bool) string {
+	if compression {
+		return "sql.gz"
+	}
+	return "sql"
+}
+
+type sqlserverEngine struct{}
+
+// ExportRequest asks for a native .bak export. Cloud SQL's Export API
+// requires BakExportOptions for FileType "BAK"; a single stripe matches the
+// single-URI shape ExportCloudSQLDatabase writes for every other engine.
+func (sqlserverEngine) ExportRequest(database, uri string) *sqladmin.ExportContext {
+	return &sqladmin.ExportContext{
+		Kind:      "sql#exportContext",
+		FileType:  "BAK",
+		Databases: []string{database},
+		Uri:       uri,
+		BakExportOptions: &sqladmin.ExportContextBakExportOptions{
+			StripeCount: 1,
+		},
+	}
+}
+
+func (sqlserverEngine) ImportRequest(database, uri, importUser string) *sqladmin.ImportContext {
+	return &sqladmin.ImportContext{
+		Kind:       "sql#importContext",
+		FileType:   "BAK",
+		Database:   database,
+		ImportUser: importUser,
+		Uri:        uri,
+		BakImportOptions: &sqladmin.ImportContextBakImportOptions{
+			StripeCount: 1,
+		},
+	}
+}
+
+func (sqlserverEngine) StatsQuery() string { return statsQuery(EngineSQLServer) }
+
+func (sqlserverEngine) SystemDatabases() []string {
+	return []string{"master", "model", "msdb", "tempdb"}
+}
+
+func (sqlserverEngine) DefaultUser() string { return "sqlserver" }
+
+// ObjectSuffix ignores compression: SQL Server's native .bak format carries
+// its own compression options (not yet wired through BakExportOptions),
+// rather than being gzipped as a second pass like the SQL dump formats.
+func (sqlserverEngine) ObjectSuffix(bool) string { return "bak" }
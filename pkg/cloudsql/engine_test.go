@@ -0,0 +1,38 @@
+package cloudsql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngineForDispatchesByDatabaseVersion(t *testing.T) {
+	test := []struct {
+		version  string
+		expected DatabaseEngine
+	}{
+		{"POSTGRES_13", postgresEngine{}},
+		{"MYSQL_8_0", mysqlEngine{}},
+		{"SQLSERVER_2019_STANDARD", sqlserverEngine{}},
+	}
+
+	for _, tt := range test {
+		assert.Equal(t, tt.expected, engineFor(tt.version))
+	}
+}
+
+func TestSQLServerExportRequestUsesBakFileType(t *testing.T) {
+	req := sqlserverEngine{}.ExportRequest("mydb", "gs://bucket/mydb.bak")
+	assert.Equal(t, "BAK", req.FileType)
+	assert.NotNil(t, req.BakExportOptions)
+	assert.EqualValues(t, 1, req.BakExportOptions.StripeCount)
+}
+
+func TestSQLServerObjectSuffixIgnoresCompression(t *testing.T) {
+	assert.Equal(t, "bak", sqlserverEngine{}.ObjectSuffix(true))
+	assert.Equal(t, "bak", sqlserverEngine{}.ObjectSuffix(false))
+}
+
+func TestMySQLSystemDatabasesAreSkipped(t *testing.T) {
+	assert.ElementsMatch(t, []string{"mysql", "information_schema", "performance_schema", "sys"}, mysqlEngine{}.SystemDatabases())
+}
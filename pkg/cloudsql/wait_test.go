@@ -0,0 +1,17 @@
+package cloudsql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJitterStaysWithinTwentyPercent(t *testing.T) {
+	const d = 10 * time.Second
+	for i := 0; i < 100; i++ {
+		got := jitter(d)
+		assert.GreaterOrEqual(t, got, 8*time.Second)
+		assert.LessOrEqual(t, got, 12*time.Second)
+	}
+}
@@ -0,0 +1,61 @@
+// Package logging builds the slog.Handler cloudsql-exporter's CLI installs
+// as its default logger, picking the wire format operators need (a human
+// scanning a terminal vs. a log aggregator ingesting JSON) and stamping
+// every record with the trace ID of whatever OpenTelemetry span is active
+// on its context, so a slow Cloud SQL operation's logs and its trace line
+// up in whatever backend ingests both.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/dusted-go/logging/prettylog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewHandler builds the slog.Handler for format, one of "pretty" (the
+// existing human-readable default), "json", or "logfmt".
+func NewHandler(w io.Writer, format string, level slog.Leveler) (slog.Handler, error) {
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch format {
+	case "", "pretty":
+		handler = prettylog.NewHandler(opts)
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	case "logfmt":
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q: must be pretty, json or logfmt", format)
+	}
+
+	return &traceHandler{Handler: handler}, nil
+}
+
+// traceHandler adds a trace_id attribute to every record whose context
+// carries a valid OpenTelemetry span, so pkg/tracing's spans and slog's
+// records can be correlated in a log aggregator. Records logged without a
+// span in context (the common case outside the restore pipeline) pass
+// through unchanged.
+type traceHandler struct {
+	slog.Handler
+}
+
+func (h *traceHandler) Handle(ctx context.Context, record slog.Record) error {
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &traceHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *traceHandler) WithGroup(name string) slog.Handler {
+	return &traceHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,24 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHandlerRejectsUnknownFormat(t *testing.T) {
+	_, err := NewHandler(&bytes.Buffer{}, "xml", slog.LevelInfo)
+	assert.ErrorContains(t, err, "invalid log format")
+}
+
+func TestNewHandlerJSONWritesRecords(t *testing.T) {
+	var buf bytes.Buffer
+	handler, err := NewHandler(&buf, "json", slog.LevelInfo)
+	assert.NoError(t, err)
+
+	slog.New(handler).Info("hello", "key", "value")
+	assert.Contains(t, buf.String(), `"msg":"hello"`)
+	assert.Contains(t, buf.String(), `"key":"value"`)
+}
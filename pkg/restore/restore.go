@@ -4,10 +4,10 @@ import (
 	"context"
 	"log/slog"
 
-	secretmanager "cloud.google.com/go/secretmanager/apiv1beta2"
-	"cloud.google.com/go/storage"
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/fr12k/cloudsql-exporter/pkg/cloudsql"
-	"google.golang.org/api/sqladmin/v1"
+	"github.com/fr12k/cloudsql-exporter/pkg/tracing"
 )
 
 type RestoreOptions struct {
@@ -17,43 +17,99 @@ type RestoreOptions struct {
 	File     string
 	User     string
 
+	// Password connects to the source instance so Restore can verify the
+	// restored data against it. Empty skips verification.
+	Password string
+	// StoreSecret saves the restore instance's generated root password to
+	// Secret Manager.
+	StoreSecret bool
+	// Cleanup, combined with Password, deletes the ephemeral restore
+	// instance once verification passes.
+	Cleanup bool
+	// SecretReplication controls how the password secret StoreSecret saves
+	// is replicated. The zero value replicates to a single user-managed
+	// replica in Region.
+	SecretReplication cloudsql.SecretReplication
+
+	// Timestamp, when set (RFC3339), switches Restore into point-in-time
+	// recovery mode using Cloud SQL's native clone API. Empty replays File
+	// as-is.
+	Timestamp string
+	// PointInTime, when set (RFC3339), switches Restore into GCS-generation
+	// based point-in-time recovery instead of Timestamp. Takes precedence
+	// over Timestamp when both are set.
+	PointInTime string
+
+	// DeepVerify, when set, recomputes a per-table content checksum on the
+	// restore instance and compares it against the backup's stats file, in
+	// addition to the row-count comparison that always runs.
+	DeepVerify bool
+	// StatsParallelism bounds how many tables' checksums DeepVerify
+	// computes concurrently. <= 0 behaves as 1.
+	StatsParallelism int
+
+	// KMSKey, when set, tells Restore that File was encrypted with this
+	// Cloud KMS key by backup.BackupOptions.KMSKey and must be decrypted
+	// before import.
+	KMSKey string
+
+	// Region is the GCP region CloudSQL creates the restore instance and
+	// password secret in. Defaults to "europe-west3" when empty.
+	Region string
+
 	Version string
+
+	// ClientOptions configures the sqladmin, storage and secretmanager
+	// clients Restore builds, e.g. cloudsql.WithTokenSource to run against a
+	// non-GCP environment instead of only Application Default Credentials.
+	ClientOptions []cloudsql.Option
 }
 
 func Restore(opts *RestoreOptions) ([]string, error) {
-	var backupPaths []string
-
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	sqlAdminSvc, err := sqladmin.NewService(ctx)
+	initCtx, initSpan := tracing.Start(ctx, "restore.init_clients",
+		attribute.String("instance", opts.Instance), attribute.String("project", opts.Project))
+	sqlAdminSvc, storageSvc, secretSvc, kmsSvc, err := cloudsql.NewClients(ctx, opts.ClientOptions...)
 	if err != nil {
-		slog.Error("error init sqladmin.Service client", "error", err)
+		slog.ErrorContext(initCtx, "error init GCP clients", "error", err)
+		initSpan.End()
 		return nil, err
 	}
+	initSpan.End()
 
-	storageSvc, err := storage.NewClient(ctx)
-	if err != nil {
-		slog.Error("init storage.Service client", "error", err)
-		return nil, err
+	region := opts.Region
+	if region == "" {
+		region = "europe-west3"
 	}
+	cls := cloudsql.NewCloudSQL(ctx, sqlAdminSvc, storageSvc, secretSvc, kmsSvc, opts.Project, region)
 
-	secretSvc, err := secretmanager.NewClient(ctx)
-	if err != nil {
-		slog.Error("init secretmanager.Service client", "error", err)
-		return nil, err
+	ropts := &cloudsql.RestoreOptions{
+		Bucket:            opts.Bucket,
+		Project:           opts.Project,
+		Instance:          opts.Instance,
+		File:              opts.File,
+		User:              opts.User,
+		Password:          opts.Password,
+		StoreSecret:       opts.StoreSecret,
+		Cleanup:           opts.Cleanup,
+		SecretReplication: opts.SecretReplication,
+		Timestamp:         opts.Timestamp,
+		PointInTime:       opts.PointInTime,
+		DeepVerify:        opts.DeepVerify,
+		StatsParallelism:  opts.StatsParallelism,
+		KMSKey:            opts.KMSKey,
+		Version:           opts.Version,
 	}
 
-	cls := cloudsql.NewCloudSQL(ctx, sqlAdminSvc, storageSvc, secretSvc, opts.Project)
-
-	//TODO store the password in GCP Secret Manager
-	password, err := cls.Restore(opts.Instance, opts.Bucket, opts.File, opts.User)
+	instanceName, err := cls.Restore(ropts)
 	if err != nil {
-		slog.Error("error validate cloudsql database", "instance", opts.Instance, "error", err)
+		slog.Error("error restore cloudsql database", "instance", opts.Instance, "error", err)
 		return nil, err
 	}
 
-	slog.Info("Backup complete", "backups", backupPaths, "password", *password)
+	slog.Info("Restore complete", "instance", *instanceName)
 
-	return backupPaths, nil
+	return []string{*instanceName}, nil
 }
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fr12k/cloudsql-exporter/pkg/backup"
+)
+
+// JobStatus is the lifecycle state of an enqueued backup job.
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// Job tracks a single POST /backup/enqueue request through the worker pool,
+// polled via GET /jobs/{id}.
+type Job struct {
+	ID     string    `json:"id"`
+	Status JobStatus `json:"status"`
+	Paths  []string  `json:"paths,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// jobQueue is a bounded worker pool for backup jobs, so a burst of
+// concurrent POST /backup/enqueue requests can't stampede the Cloud SQL API.
+type jobQueue struct {
+	work chan *queuedJob
+
+	mu  sync.Mutex
+	job map[string]*Job
+
+	nextID atomic.Uint64
+}
+
+type queuedJob struct {
+	job  *Job
+	opts *backup.BackupOptions
+}
+
+func newJobQueue(workers int) *jobQueue {
+	if workers < 1 {
+		workers = 1
+	}
+
+	q := &jobQueue{
+		work: make(chan *queuedJob, 64),
+		job:  make(map[string]*Job),
+	}
+
+	for i := 0; i < workers; i++ {
+		go q.run()
+	}
+
+	return q
+}
+
+func (q *jobQueue) enqueue(opts *backup.BackupOptions) *Job {
+	id := fmt.Sprintf("job-%d", q.nextID.Add(1))
+	job := &Job{ID: id, Status: JobQueued}
+
+	q.mu.Lock()
+	q.job[id] = job
+	q.mu.Unlock()
+
+	q.work <- &queuedJob{job: job, opts: opts}
+	return job
+}
+
+func (q *jobQueue) get(id string) (*Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.job[id]
+	return job, ok
+}
+
+func (q *jobQueue) run() {
+	for qj := range q.work {
+		q.mu.Lock()
+		qj.job.Status = JobRunning
+		q.mu.Unlock()
+
+		start := time.Now()
+		paths, err := backup.Backup(qj.opts)
+		recordBackupResult(qj.opts.Instance, time.Since(start), err)
+
+		q.mu.Lock()
+		if err != nil {
+			slog.Error("enqueued backup job failed", "job", qj.job.ID, "instance", qj.opts.Instance, "error", err)
+			qj.job.Status = JobFailed
+			qj.job.Error = err.Error()
+		} else {
+			qj.job.Status = JobSucceeded
+			qj.job.Paths = paths
+		}
+		q.mu.Unlock()
+	}
+}
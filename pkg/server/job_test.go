@@ -0,0 +1,25 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fr12k/cloudsql-exporter/pkg/backup"
+)
+
+func TestJobQueueEnqueueAssignsUniqueIDs(t *testing.T) {
+	q := newJobQueue(1)
+
+	job1 := q.enqueue(&backup.BackupOptions{Instance: "test-instance"})
+	job2 := q.enqueue(&backup.BackupOptions{Instance: "test-instance"})
+
+	assert.NotEqual(t, job1.ID, job2.ID)
+
+	got1, ok := q.get(job1.ID)
+	assert.True(t, ok)
+	assert.Equal(t, job1.ID, got1.ID)
+
+	_, ok = q.get("does-not-exist")
+	assert.False(t, ok)
+}
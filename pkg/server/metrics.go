@@ -0,0 +1,50 @@
+package server
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/fr12k/cloudsql-exporter/pkg/cloudsql"
+)
+
+// Metrics recorded by every path that runs a backup or restore (the
+// synchronous and enqueued HTTP handlers, and the Scheduler), exposed on
+// GET /metrics for a Prometheus scrape.
+var (
+	backupDurationSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "cloudsql_backup_duration_seconds",
+		Help: "How long a backup took to export, labeled by instance.",
+	}, []string{"instance"})
+
+	backupLastSuccessTimestamp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cloudsql_backup_last_success_timestamp",
+		Help: "Unix timestamp of the last successful backup, labeled by instance.",
+	}, []string{"instance"})
+
+	restoreVerifyFailuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cloudsql_restore_verify_failures_total",
+		Help: "Count of restores whose post-import verification against the source instance failed, labeled by instance.",
+	}, []string{"instance"})
+)
+
+// recordBackupResult updates the backup metrics for instance once a backup
+// attempt finishes, regardless of whether it ran synchronously, on the job
+// queue, or on a Scheduler tick.
+func recordBackupResult(instance string, duration time.Duration, err error) {
+	backupDurationSeconds.WithLabelValues(instance).Observe(duration.Seconds())
+	if err == nil {
+		backupLastSuccessTimestamp.WithLabelValues(instance).SetToCurrentTime()
+	}
+}
+
+// recordRestoreVerifyFailure increments restoreVerifyFailuresTotal for
+// instance when err wraps cloudsql.ErrVerificationFailed, leaving the
+// counter untouched for every other kind of restore error.
+func recordRestoreVerifyFailure(instance string, err error) {
+	if errors.Is(err, cloudsql.ErrVerificationFailed) {
+		restoreVerifyFailuresTotal.WithLabelValues(instance).Inc()
+	}
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"gopkg.in/yaml.v3"
+
+	"github.com/fr12k/cloudsql-exporter/pkg/backup"
+)
+
+// ScheduleEntry is one scheduled backup in a ScheduleConfig: back up
+// Instance in Project to Bucket every time Schedule (a standard 5-field
+// cron expression) fires.
+type ScheduleEntry struct {
+	Project  string `yaml:"project"`
+	Instance string `yaml:"instance"`
+	Bucket   string `yaml:"bucket"`
+	Schedule string `yaml:"schedule"`
+
+	// User and Password, when both set, additionally export table
+	// statistics for restore-integrity checking, the same as
+	// backup.BackupOptions.ExportStats.
+	User     string `yaml:"user,omitempty"`
+	Password string `yaml:"password,omitempty"`
+
+	Compression bool `yaml:"compression,omitempty"`
+	Checksum    bool `yaml:"checksum,omitempty"`
+}
+
+func (e ScheduleEntry) toBackupOptions() backup.BackupOptions {
+	return backup.BackupOptions{
+		Bucket:      e.Bucket,
+		Project:     e.Project,
+		Instance:    e.Instance,
+		User:        e.User,
+		Password:    e.Password,
+		ExportStats: e.User != "" && e.Password != "",
+		Compression: e.Compression,
+		Checksum:    e.Checksum,
+	}
+}
+
+// ScheduleConfig lists every scheduled backup a daemon-mode serve process
+// should run, typically one entry per Cloud SQL instance.
+type ScheduleConfig struct {
+	Backups []ScheduleEntry `yaml:"backups"`
+}
+
+// LoadScheduleConfig reads and parses a ScheduleConfig from a YAML file.
+func LoadScheduleConfig(path string) (*ScheduleConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read schedule config %q: %w", path, err)
+	}
+
+	var cfg ScheduleConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse schedule config %q: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Scheduler runs every ScheduleEntry in a ScheduleConfig on its own cron
+// schedule, invoking backup.Backup and recording the same metrics the HTTP
+// control-plane's handlers do. This is what lets a serve process configured
+// with a schedule config replace an external cron daemon entirely.
+type Scheduler struct {
+	cron *cron.Cron
+}
+
+// NewScheduler builds a Scheduler and registers every entry in cfg, but
+// doesn't run any of them until Start is called.
+func NewScheduler(cfg *ScheduleConfig) (*Scheduler, error) {
+	c := cron.New()
+	for _, entry := range cfg.Backups {
+		entry := entry
+		if _, err := c.AddFunc(entry.Schedule, func() { runScheduledBackup(entry) }); err != nil {
+			return nil, fmt.Errorf("invalid schedule %q for instance %q: %w", entry.Schedule, entry.Instance, err)
+		}
+	}
+	return &Scheduler{cron: c}, nil
+}
+
+// Start begins running every registered schedule. It returns immediately;
+// schedules fire on cron's own goroutine as they come due.
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Stop stops scheduling new runs and waits for any in-flight scheduled
+// backup to finish.
+func (s *Scheduler) Stop() {
+	<-s.cron.Stop().Done()
+}
+
+func runScheduledBackup(entry ScheduleEntry) {
+	slog.Info("Running scheduled backup", "project", entry.Project, "instance", entry.Instance, "bucket", entry.Bucket)
+
+	opts := entry.toBackupOptions()
+	start := time.Now()
+	_, err := backup.Backup(&opts)
+	recordBackupResult(entry.Instance, time.Since(start), err)
+
+	if err != nil {
+		slog.Error("Scheduled backup failed", "instance", entry.Instance, "error", err)
+		return
+	}
+	slog.Info("Scheduled backup complete", "instance", entry.Instance)
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadScheduleConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule.yaml")
+	data := []byte(`backups:
+  - project: my-project
+    instance: my-instance
+    bucket: my-bucket
+    schedule: "0 3 * * *"
+`)
+	assert.NoError(t, os.WriteFile(path, data, 0o600))
+
+	cfg, err := LoadScheduleConfig(path)
+	assert.NoError(t, err)
+	assert.Len(t, cfg.Backups, 1)
+	assert.Equal(t, "my-instance", cfg.Backups[0].Instance)
+	assert.Equal(t, "0 3 * * *", cfg.Backups[0].Schedule)
+}
+
+func TestNewSchedulerRejectsInvalidCronExpression(t *testing.T) {
+	cfg := &ScheduleConfig{Backups: []ScheduleEntry{{Instance: "bad-instance", Schedule: "not-a-cron-expression"}}}
+	_, err := NewScheduler(cfg)
+	assert.Error(t, err)
+}
@@ -0,0 +1,217 @@
+// Package server exposes a small HTTP control-plane around CloudSQL so
+// backups and restores can be driven by webhook/cron/CI instead of only by
+// CLI invocation.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/fr12k/cloudsql-exporter/pkg/backup"
+	"github.com/fr12k/cloudsql-exporter/pkg/cloudsql"
+)
+
+// Server is the HTTP control-plane. It owns the same set of GCP clients
+// pkg/backup.Backup and pkg/restore.Restore each construct for themselves,
+// so handlers can drive CloudSQL directly without round-tripping through the
+// CLI subcommands.
+type Server struct {
+	ProjectID string
+
+	cls  *cloudsql.CloudSQL
+	jobs *jobQueue
+}
+
+// New builds a Server and starts its bounded backup worker pool. workers
+// caps how many POST /backup/enqueue jobs run against the Cloud SQL API at
+// once, so a burst of webhook/cron triggers can't stampede it. clientOpts
+// configures the underlying GCP clients, e.g. cloudsql.WithTokenSource to
+// run against a non-GCP environment instead of only Application Default
+// Credentials.
+func New(ctx context.Context, projectID, region string, workers int, clientOpts ...cloudsql.Option) (*Server, error) {
+	sqlAdminSvc, storageSvc, secretSvc, kmsSvc, err := cloudsql.NewClients(ctx, clientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("init GCP clients: %w", err)
+	}
+
+	cls := cloudsql.NewCloudSQL(ctx, sqlAdminSvc, storageSvc, secretSvc, kmsSvc, projectID, region)
+
+	s := &Server{
+		ProjectID: projectID,
+		cls:       cls,
+		jobs:      newJobQueue(workers),
+	}
+	return s, nil
+}
+
+// Routes returns the control-plane's HTTP handler.
+func (s *Server) Routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /backup/{how}", s.handleBackup)
+	mux.HandleFunc("POST /restore", s.handleRestore)
+	mux.HandleFunc("GET /operations/{id}", s.handleOperation)
+	mux.HandleFunc("GET /jobs/{id}", s.handleJob)
+	mux.Handle("GET /metrics", promhttp.Handler())
+	mux.HandleFunc("GET /healthz", handleHealthz)
+	return mux
+}
+
+// handleHealthz implements GET /healthz, a liveness probe for the
+// Kubernetes/Cloud Run deployments this control-plane is meant to run
+// under. It reports healthy as long as the process is serving requests at
+// all, since CloudSQL's own clients are re-dialed per call and have no
+// persistent connection state to check.
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
+
+// backupRequest is the JSON body for POST /backup/{how}.
+type backupRequest struct {
+	Project     string   `json:"project"`
+	Instance    string   `json:"instance"`
+	Bucket      string   `json:"bucket"`
+	Databases   []string `json:"databases"`
+	User        string   `json:"user"`
+	Password    string   `json:"password"`
+	ExportStats bool     `json:"export_stats"`
+	Compression bool     `json:"compression"`
+	Checksum    bool     `json:"checksum"`
+}
+
+func (r backupRequest) toBackupOptions() backup.BackupOptions {
+	return backup.BackupOptions{
+		Bucket:      r.Bucket,
+		Project:     r.Project,
+		Instance:    r.Instance,
+		User:        r.User,
+		Password:    r.Password,
+		ExportStats: r.ExportStats,
+		Compression: r.Compression,
+		Checksum:    r.Checksum,
+	}
+}
+
+// handleBackup implements POST /backup/{how}, how being "now" (run
+// synchronously and return the resulting backup paths) or "enqueue" (queue
+// the job on the bounded worker pool and return immediately with a job ID to
+// poll via GET /jobs/{id}).
+func (s *Server) handleBackup(w http.ResponseWriter, r *http.Request) {
+	how := r.PathValue("how")
+	if how != "now" && how != "enqueue" {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown backup mode %q, want now or enqueue", how))
+		return
+	}
+
+	var req backupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("decode request body: %w", err))
+		return
+	}
+
+	if err := s.requireInstance(req.Instance); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	opts := req.toBackupOptions()
+
+	if how == "now" {
+		start := time.Now()
+		paths, err := backup.Backup(&opts)
+		recordBackupResult(req.Instance, time.Since(start), err)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"paths": paths})
+		return
+	}
+
+	job := s.jobs.enqueue(&opts)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// handleRestore implements POST /restore, running the restore synchronously
+// against cloudsql.RestoreOptions decoded from the request body.
+func (s *Server) handleRestore(w http.ResponseWriter, r *http.Request) {
+	var opts cloudsql.RestoreOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("decode request body: %w", err))
+		return
+	}
+
+	if err := s.requireInstance(opts.Instance); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	instanceName, err := s.cls.Restore(&opts)
+	if err != nil {
+		recordRestoreVerifyFailure(opts.Instance, err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"instance": instanceName})
+}
+
+// handleOperation implements GET /operations/{id}, a non-blocking wrapper
+// around the same sqladmin.Operations.Get call CloudSQL.WaitForSQLOperation
+// polls in a loop, so callers can probe a long-running operation's status
+// without blocking on it.
+func (s *Server) handleOperation(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	op, err := s.cls.GetOperation(id)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, op)
+}
+
+// handleJob implements GET /jobs/{id}, reporting the status of a backup job
+// previously enqueued via POST /backup/enqueue.
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	job, ok := s.jobs.get(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown job %q", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// requireInstance rejects a request naming an instance CloudSQL doesn't know
+// about, so a typo'd instance name fails fast instead of queuing work that
+// can never succeed.
+func (s *Server) requireInstance(instance string) error {
+	instances, err := s.cls.EnumerateCloudSQLDatabaseInstances(instance)
+	if err != nil {
+		return fmt.Errorf("enumerate cloudsql instances: %w", err)
+	}
+	if len(instances) == 0 {
+		return fmt.Errorf("instance %q not found", instance)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.Error("error write response body", "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	slog.Error("server error", "status", status, "error", err)
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
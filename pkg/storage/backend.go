@@ -0,0 +1,274 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cloud.google.com/go/iam"
+	gcs "cloud.google.com/go/storage"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Backend abstracts the object-storage system a backup is archived to, so the
+// exported database objects can be archived to GCS, S3, Azure Blob, or the
+// local filesystem interchangeably. The exporter's own artifacts (stats,
+// users, manifest) are written to the GCS bucket, not to Backend.
+//
+// Cloud SQL's native instance export API only ever writes to a gs:// URI, so
+// non-GCS backends are used as the archive destination for a post-export
+// sync rather than as the direct target of Instances.Export; see
+// pkg/backup.Backup.
+type Backend interface {
+	// URI returns the fully qualified location of loc's backup path on this
+	// backend, e.g. "s3://bucket/instance/cloudsql/".
+	URI(loc Location, kind string) string
+	Upload(ctx context.Context, key string, r io.Reader) error
+	Download(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// EnsureWriter grants the given Cloud SQL service account permission to
+	// write to this backend's destination. Backends with no native IAM
+	// concept (S3, Azure Blob, local FS) are no-ops.
+	EnsureWriter(serviceAccount string) error
+}
+
+// NewBackend parses a --destination URL of the form gs://..., s3://...,
+// az://... or file://... and constructs the matching Backend.
+func NewBackend(ctx context.Context, destination string) (Backend, error) {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return nil, fmt.Errorf("parse destination %q: %w", destination, err)
+	}
+
+	switch u.Scheme {
+	case "gs":
+		return NewGCSBackend(ctx, u.Host)
+	case "s3":
+		return NewS3Backend(ctx, u.Host)
+	case "az":
+		accountURL := fmt.Sprintf("https://%s.blob.core.windows.net", u.Host)
+		return NewAzureBlobBackend(accountURL, strings.TrimPrefix(u.Path, "/"))
+	case "file":
+		return NewFileBackend(filepath.Join(u.Host, u.Path)), nil
+	default:
+		return nil, fmt.Errorf("unsupported destination scheme %q", u.Scheme)
+	}
+}
+
+// GCSBackend archives backups to a Google Cloud Storage bucket. This is the
+// backend sqladmin.Instances.Export always writes to, regardless of the
+// configured destination.
+type GCSBackend struct {
+	Bucket string
+	Client *gcs.Client
+}
+
+func NewGCSBackend(ctx context.Context, bucket string) (*GCSBackend, error) {
+	client, err := gcs.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &GCSBackend{Bucket: bucket, Client: client}, nil
+}
+
+func (b *GCSBackend) URI(loc Location, kind string) string {
+	return fmt.Sprintf("gs://%s/%s", b.Bucket, loc.Path)
+}
+
+func (b *GCSBackend) Upload(ctx context.Context, key string, r io.Reader) error {
+	w := b.Client.Bucket(b.Bucket).Object(key).NewWriter(ctx)
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (b *GCSBackend) Download(ctx context.Context, key string) (io.ReadCloser, error) {
+	return b.Client.Bucket(b.Bucket).Object(key).NewReader(ctx)
+}
+
+func (b *GCSBackend) Delete(ctx context.Context, key string) error {
+	return b.Client.Bucket(b.Bucket).Object(key).Delete(ctx)
+}
+
+func (b *GCSBackend) EnsureWriter(serviceAccount string) error {
+	return b.setRole(serviceAccount, true)
+}
+
+// RemoveWriter revokes the role bindings granted by EnsureWriter. It is not
+// part of the Backend interface since only GCS IAM supports temporary,
+// per-run bindings; callers should type-assert for it.
+func (b *GCSBackend) RemoveWriter(serviceAccount string) error {
+	return b.setRole(serviceAccount, false)
+}
+
+func (b *GCSBackend) setRole(serviceAccount string, grant bool) error {
+	ctx := context.Background()
+	bucket := b.Client.Bucket(b.Bucket)
+	policy, err := bucket.IAM().Policy(ctx)
+	if err != nil {
+		return err
+	}
+
+	member := fmt.Sprintf("serviceAccount:%s", serviceAccount)
+	for _, role := range []iam.RoleName{"roles/storage.objectCreator", "roles/storage.objectViewer"} {
+		if grant {
+			policy.Add(member, role)
+		} else {
+			policy.Remove(member, role)
+		}
+	}
+
+	return bucket.IAM().SetPolicy(ctx, policy)
+}
+
+// S3Backend archives backups to an AWS S3 bucket.
+type S3Backend struct {
+	Bucket string
+	Client *s3.Client
+}
+
+func NewS3Backend(ctx context.Context, bucket string) (*S3Backend, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &S3Backend{Bucket: bucket, Client: s3.NewFromConfig(cfg)}, nil
+}
+
+func (b *S3Backend) URI(loc Location, kind string) string {
+	return fmt.Sprintf("s3://%s/%s", b.Bucket, loc.Path)
+}
+
+func (b *S3Backend) Upload(ctx context.Context, key string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	_, err = b.Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(b.Bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	})
+	return err
+}
+
+func (b *S3Backend) Download(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := b.Client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(b.Bucket), Key: aws.String(key)})
+	if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
+
+func (b *S3Backend) Delete(ctx context.Context, key string) error {
+	_, err := b.Client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(b.Bucket), Key: aws.String(key)})
+	return err
+}
+
+// EnsureWriter is a no-op: Cloud SQL service accounts have no native AWS
+// identity, so granting S3 access is expected to be managed out of band
+// (e.g. via an IAM role mapped through workload identity federation).
+func (b *S3Backend) EnsureWriter(serviceAccount string) error {
+	return nil
+}
+
+// AzureBlobBackend archives backups to an Azure Blob Storage container.
+type AzureBlobBackend struct {
+	Container string
+	Client    *azblob.Client
+}
+
+func NewAzureBlobBackend(accountURL, container string) (*AzureBlobBackend, error) {
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, err
+	}
+	client, err := azblob.NewClient(accountURL, cred, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &AzureBlobBackend{Container: container, Client: client}, nil
+}
+
+func (b *AzureBlobBackend) URI(loc Location, kind string) string {
+	return fmt.Sprintf("az://%s/%s", b.Container, loc.Path)
+}
+
+func (b *AzureBlobBackend) Upload(ctx context.Context, key string, r io.Reader) error {
+	_, err := b.Client.UploadStream(ctx, b.Container, key, r, nil)
+	return err
+}
+
+func (b *AzureBlobBackend) Download(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := b.Client.DownloadStream(ctx, b.Container, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (b *AzureBlobBackend) Delete(ctx context.Context, key string) error {
+	_, err := b.Client.DeleteBlob(ctx, b.Container, key, nil)
+	return err
+}
+
+// EnsureWriter is a no-op: access is expected to be granted out of band via
+// an Azure AD workload identity federation mapping for the Cloud SQL
+// service account.
+func (b *AzureBlobBackend) EnsureWriter(serviceAccount string) error {
+	return nil
+}
+
+// FileBackend archives backups to a local (or network-mounted) directory.
+// It exists mainly for tests and for single-host deployments.
+type FileBackend struct {
+	BaseDir string
+}
+
+func NewFileBackend(baseDir string) *FileBackend {
+	return &FileBackend{BaseDir: baseDir}
+}
+
+func (b *FileBackend) URI(loc Location, kind string) string {
+	return fmt.Sprintf("file://%s", filepath.Join(b.BaseDir, loc.Path))
+}
+
+func (b *FileBackend) Upload(ctx context.Context, key string, r io.Reader) error {
+	dest := filepath.Join(b.BaseDir, key)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, r)
+	return err
+}
+
+func (b *FileBackend) Download(ctx context.Context, key string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(b.BaseDir, key))
+}
+
+func (b *FileBackend) Delete(ctx context.Context, key string) error {
+	return os.Remove(filepath.Join(b.BaseDir, key))
+}
+
+// EnsureWriter is a no-op: filesystem permissions are managed by whatever
+// mounts BaseDir into the exporter's runtime environment.
+func (b *FileBackend) EnsureWriter(serviceAccount string) error {
+	return nil
+}
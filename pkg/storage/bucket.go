@@ -12,6 +12,15 @@ func databaseFromFile(file string) string {
 	return strings.Join(ss[:len(ss)-1], "-")
 }
 
+// Kind distinguishes a full instance dump from an incremental export chained
+// off a previous backup via BackupOptions.LastBackupTime.
+type Kind string
+
+const (
+	KindFull        Kind = "full"
+	KindIncremental Kind = "incremental"
+)
+
 type Location struct {
 	Bucket      string
 	Database    string
@@ -19,6 +28,12 @@ type Location struct {
 	Path        string
 	Time        string
 	Compression bool
+	Kind        Kind // zero value behaves as KindFull
+
+	// Suffix overrides DatabaseLocation's file extension (e.g. "bak" for a
+	// SQL Server native backup). Empty keeps the default "sql"/"sql.gz"
+	// behavior driven by Compression.
+	Suffix string
 }
 
 func (b Location) UserLocation() string {
@@ -29,23 +44,42 @@ func (b Location) StatsLocation(database string) string {
 	return fmt.Sprintf("%sstats-%s-%s.yaml", b.Path, database, b.Time)
 }
 
+// ManifestLocation is the per-backup manifest object, shared by all databases
+// exported as part of the same backup run.
+func (b Location) ManifestLocation() string {
+	return fmt.Sprintf("%smanifest-%s.json", b.Path, b.Time)
+}
+
 func (b Location) DatabaseLocation(database string) string {
-	suffix := "sql"
-	if b.Compression {
-		suffix = "sql.gz"
+	suffix := b.Suffix
+	if suffix == "" {
+		suffix = "sql"
+		if b.Compression {
+			suffix = "sql.gz"
+		}
 	}
-	return fmt.Sprintf("gs://%s/%s/cloudsql/%s-%s.%s", b.Bucket, b.Instance, database, b.Time, suffix)
+	kindSuffix := ""
+	if b.Kind == KindIncremental {
+		kindSuffix = ".incr"
+	}
+	return fmt.Sprintf("gs://%s/%s/cloudsql/%s-%s%s.%s", b.Bucket, b.Instance, database, b.Time, kindSuffix, suffix)
 }
 
 // NewLocation parse the location metadata from the file path.
 // Valid file path should be in the format of gs://flink-backup-bucket-flink-platform-staging/payment-events/cloudsql/payment-service-20240417T150207.sql.gz
+// or, for an incremental export, .../payment-events-20240404T152957.incr.sql.gz
 func NewLocation(file string) Location {
 	bucket := strings.Split(file, "/")[2]
 	ss := strings.Split(filepath.Dir(file), "/")
 	instance := ss[2]
 	path := strings.Join(ss[2:], "/") + "/"
 	ss = strings.Split(filepath.Base(file), "-")
-	time := strings.Split(ss[len(ss)-1], ".")[0]
+	timeAndSuffix := strings.Split(ss[len(ss)-1], ".")
+	time := timeAndSuffix[0]
+	kind := KindFull
+	if len(timeAndSuffix) > 1 && timeAndSuffix[1] == "incr" {
+		kind = KindIncremental
+	}
 	database := databaseFromFile(file)
 	return Location{
 		Bucket:   bucket,
@@ -53,5 +87,6 @@ func NewLocation(file string) Location {
 		Instance: instance,
 		Database: database,
 		Time:     time,
+		Kind:     kind,
 	}
 }
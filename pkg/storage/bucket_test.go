@@ -39,6 +39,7 @@ func TestFileTemplate(t *testing.T) {
 				Instance: "dc-stock-level-service",
 				Database: "dc-stock-level-service",
 				Time:     "20240404T152957",
+				Kind:     KindFull,
 			},
 		},
 		{
@@ -49,6 +50,7 @@ func TestFileTemplate(t *testing.T) {
 				Instance: "pricing",
 				Database: "pricing",
 				Time:     "20240404T152957",
+				Kind:     KindFull,
 			},
 		},
 		{
@@ -59,6 +61,18 @@ func TestFileTemplate(t *testing.T) {
 				Instance: "payment-service",
 				Database: "payment-events",
 				Time:     "20240404T152957",
+				Kind:     KindFull,
+			},
+		},
+		{
+			file: "gs://flink-backup-bucket-flink-platform-staging/payment-service/cloudsql/payment-events-20240404T152957.incr.sql.gz",
+			expected: Location{
+				Bucket:   "flink-backup-bucket-flink-platform-staging",
+				Path:     "payment-service/cloudsql/",
+				Instance: "payment-service",
+				Database: "payment-events",
+				Time:     "20240404T152957",
+				Kind:     KindIncremental,
 			},
 		},
 	}
@@ -100,4 +114,18 @@ func TestDatabaseLocation(t *testing.T) {
 
 	loc.Compression = false
 	assert.Equal(t, "gs://flink-backup-bucket-flink-platform-staging/payment-service/cloudsql/payment-events-20240404T152957.sql", loc.DatabaseLocation("payment-events"))
+
+	loc.Compression = true
+	loc.Kind = KindIncremental
+	assert.Equal(t, "gs://flink-backup-bucket-flink-platform-staging/payment-service/cloudsql/payment-events-20240404T152957.incr.sql.gz", loc.DatabaseLocation("payment-events"))
+}
+
+func TestManifestLocation(t *testing.T) {
+	loc := Location{
+		Bucket: "flink-backup-bucket-flink-platform-staging",
+		Path:   "pricing/cloudsql/",
+		Time:   "20240404T152957",
+	}
+
+	assert.Equal(t, "pricing/cloudsql/manifest-20240404T152957.json", loc.ManifestLocation())
 }
@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/crc32"
+	"io"
+)
+
+// Digest is the streaming checksum of a backup object, recorded in its
+// Manifest so Restore can re-hash the archived object and detect corruption
+// or tampering before importing it.
+type Digest struct {
+	Size int64 `json:"size"`
+	// SHA256 is the hex-encoded digest of the object's full contents.
+	SHA256 string `json:"sha256"`
+	// CRC32C is the hex-encoded Castagnoli CRC32 checksum, the same
+	// polynomial GCS uses for its own object checksums.
+	CRC32C string `json:"crc32c,omitempty"`
+}
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// DigestReader streams r, computing its size, SHA-256 and CRC32C without
+// buffering the whole object in memory.
+func DigestReader(r io.Reader) (Digest, error) {
+	sha := sha256.New()
+	crc := crc32.New(crc32cTable)
+
+	n, err := io.Copy(io.MultiWriter(sha, crc), r)
+	if err != nil {
+		return Digest{}, err
+	}
+
+	return Digest{
+		Size:   n,
+		SHA256: hex.EncodeToString(sha.Sum(nil)),
+		CRC32C: hex.EncodeToString(crc.Sum(nil)),
+	}, nil
+}
@@ -0,0 +1,16 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDigestReader(t *testing.T) {
+	d, err := DigestReader(strings.NewReader("hello world"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), d.Size)
+	assert.Equal(t, "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9", d.SHA256)
+	assert.NotEmpty(t, d.CRC32C)
+}
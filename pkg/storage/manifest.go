@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TableCursor records the incremental watermark used to select rows that
+// changed since the previous backup for a single table.
+type TableCursor struct {
+	Column        string `json:"column"`
+	HighWaterMark string `json:"high_water_mark"`
+}
+
+// ObjectChecksum records the integrity digest of a single exported backup
+// object, written when BackupOptions.Checksum is set so Restore can re-hash
+// the object before importing it and reject a corrupted or tampered archive.
+type ObjectChecksum struct {
+	URI      string `json:"uri"`
+	Database string `json:"database"`
+	Digest
+}
+
+// Manifest is written alongside every backup (full or incremental) so that a
+// restore can chain a base backup with any number of subsequent increments.
+type Manifest struct {
+	BaseBackup    string                 `json:"base_backup"`
+	Parents       []string               `json:"parents,omitempty"`
+	Tables        map[string]TableCursor `json:"tables,omitempty"`
+	EngineVersion string                 `json:"engine_version"`
+	CreatedAt     time.Time              `json:"created_at"`
+
+	// Objects holds a checksum entry per exported database object, present
+	// only when the backup was taken with --checksum.
+	Objects []ObjectChecksum `json:"objects,omitempty"`
+	// RowCounts is the table -> row-count snapshot from
+	// CloudSQL.ExportCloudSQLStatistics at backup time, keyed the same way as
+	// CloudSQLStatistic (schema.table). Present only alongside Objects.
+	RowCounts map[string]int64 `json:"row_counts,omitempty"`
+	// Version is the cloudsql-exporter build version that produced this backup.
+	Version string `json:"version,omitempty"`
+
+	// Retain, when true, exempts this backup's BaseBackup object from
+	// pkg/backup.Prune's retention policy, e.g. for a legal hold. Prune also
+	// always protects every backup listed in any manifest's Parents,
+	// regardless of this flag; a BaseBackup is protected only by this flag.
+	Retain bool `json:"retain,omitempty"`
+}
+
+// FindObjectChecksum returns the recorded digest for the backup object at
+// uri. It returns false for manifests written without --checksum, or for a
+// uri it has no entry for, so callers can skip verification instead of
+// rejecting the restore of a backup taken before this feature existed.
+func (m Manifest) FindObjectChecksum(uri string) (ObjectChecksum, bool) {
+	for _, o := range m.Objects {
+		if o.URI == uri {
+			return o, true
+		}
+	}
+	return ObjectChecksum{}, false
+}
+
+// Marshal renders the manifest as indented JSON, matching the *-.yaml/*.json
+// convention already used for the other backup metadata objects.
+func (m Manifest) Marshal() ([]byte, error) {
+	return json.MarshalIndent(m, "", "  ")
+}
+
+// UnmarshalManifest parses a manifest previously written by Marshal.
+func UnmarshalManifest(data []byte) (Manifest, error) {
+	var m Manifest
+	err := json.Unmarshal(data, &m)
+	return m, err
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManifestMarshalRoundTrip(t *testing.T) {
+	m := Manifest{
+		BaseBackup: "gs://bucket/instance/cloudsql/pricing-20240404T152957.sql.gz",
+		Parents:    []string{"gs://bucket/instance/cloudsql/pricing-20240405T152957.incr.sql.gz"},
+		Tables: map[string]TableCursor{
+			"public.orders": {Column: "updated_at", HighWaterMark: "2024-04-06T15:29:57Z"},
+		},
+		EngineVersion: "POSTGRES_13",
+		CreatedAt:     time.Date(2024, 4, 6, 15, 29, 57, 0, time.UTC),
+		Objects: []ObjectChecksum{
+			{
+				URI:      "gs://bucket/instance/cloudsql/pricing-20240404T152957.sql.gz",
+				Database: "pricing",
+				Digest:   Digest{Size: 1024, SHA256: "abc123", CRC32C: "deadbeef"},
+			},
+		},
+		RowCounts: map[string]int64{"public.orders": 42},
+		Version:   "1.2.3",
+		Retain:    true,
+	}
+
+	data, err := m.Marshal()
+	assert.NoError(t, err)
+
+	got, err := UnmarshalManifest(data)
+	assert.NoError(t, err)
+	assert.Equal(t, m, got)
+}
@@ -0,0 +1,58 @@
+// Package tracing wires OpenTelemetry spans around the Cloud SQL restore
+// pipeline, so operators running cloudsql-exporter inside a larger CI/CD
+// pipeline can correlate a slow import operation with whatever upstream
+// system kicked it off.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies this package's spans in a multi-service trace.
+const tracerName = "github.com/fr12k/cloudsql-exporter"
+
+// Init configures the global OpenTelemetry tracer provider to export spans
+// to endpoint over OTLP/gRPC and returns a shutdown func that flushes and
+// closes the exporter. When endpoint is empty, it leaves the default no-op
+// tracer provider in place, so Start is always safe to call even when
+// tracing isn't configured.
+func Init(ctx context.Context, endpoint, serviceName string) (shutdown func(context.Context) error, err error) {
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithEndpoint(endpoint), otlptracegrpc.WithInsecure())
+	if err != nil {
+		return nil, fmt.Errorf("init otlp trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, fmt.Errorf("build otel resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
+
+// Start opens a span named name as a child of ctx's span (or a new trace
+// root if ctx carries none), attaching attrs, and returns the context
+// callers should pass down to both nested spans and context-aware slog
+// calls so the log record picks up the new span's trace ID.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}